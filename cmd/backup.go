@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupPassword string
+	backupOutput   string
+	backupMode     string
+	backupPlain    bool
+)
+
+// BackupCmd represents the backup command
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or import storages, users, metas, settings and task data",
+	Long: `Export or import storages, users, metas, settings and task data.
+
+To migrate from sqlite3 to postgres, export with the old config, then import with the database overridden by env:
+  alist backup export -o backup.json -p secret && ALIST_DB_TYPE=postgres ALIST_DB_HOST=127.0.0.1 ALIST_DB_PORT=5432 ALIST_DB_USER=alist ALIST_DB_PASS=alist ALIST_DB_NAME=alist alist backup import backup.json -p secret -m replace
+and update the database section of config.json afterwards.`,
+}
+
+var exportBackupCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the config to a json archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		a, err := backup.Export()
+		if err != nil {
+			utils.Log.Errorf("failed export: %+v", err)
+			return
+		}
+		var data []byte
+		if backupPlain && backupPassword == "" {
+			data, err = a.MarshalPlaintext()
+		} else {
+			data, err = a.Marshal(backupPassword)
+		}
+		if err != nil {
+			utils.Log.Errorf("failed marshal backup: %+v", err)
+			return
+		}
+		if err := os.WriteFile(backupOutput, data, 0o600); err != nil {
+			utils.Log.Errorf("failed write backup: %+v", err)
+			return
+		}
+		utils.Log.Infof("backup is exported to %s", backupOutput)
+	},
+}
+
+var importBackupCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import the config from a json archive, the server should be stopped",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("backup file is required")
+			return
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed read backup: %+v", err)
+			return
+		}
+		a, err := backup.Unmarshal(data, backupPassword)
+		if err != nil {
+			utils.Log.Errorf("%+v", err)
+			return
+		}
+		Init()
+		defer Release()
+		if err := backup.Import(context.Background(), a, backup.Mode(backupMode), false); err != nil {
+			utils.Log.Errorf("failed import: %+v", err)
+			return
+		}
+		utils.Log.Infof("backup is imported in %s mode", backupMode)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(BackupCmd)
+	BackupCmd.AddCommand(exportBackupCmd)
+	BackupCmd.AddCommand(importBackupCmd)
+	BackupCmd.PersistentFlags().StringVarP(&backupPassword, "password", "p", "", "password to encrypt or decrypt the backup")
+	exportBackupCmd.Flags().BoolVar(&backupPlain, "plaintext", false, "export without a password, the secrets in the backup are readable")
+	exportBackupCmd.Flags().StringVarP(&backupOutput, "output", "o", "alist-backup.json", "output file")
+	importBackupCmd.Flags().StringVarP(&backupMode, "mode", "m", string(backup.Merge), "import mode, merge or replace")
+}
@@ -38,6 +38,10 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitFeeds()
+		bootstrap.InitLdap()
+		bootstrap.InitHealthCheck()
+		bootstrap.InitSessionCleanup()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
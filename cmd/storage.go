@@ -4,10 +4,12 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"os"
 	"strconv"
 
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/manifest"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
@@ -47,6 +49,64 @@ var disableStorageCmd = &cobra.Command{
 	},
 }
 
+var (
+	manifestFile   string
+	manifestDryRun bool
+	manifestPrune  bool
+)
+
+var applyStorageCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update storages from a yaml or json manifest",
+	Long: `Create or update storages from a yaml or json manifest, the storages are matched by mount path.
+The changes take effect after restart if the server is running, or apply the manifest by the api instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if manifestFile == "" {
+			utils.Log.Errorf("manifest file is required")
+			return
+		}
+		data, err := os.ReadFile(manifestFile)
+		if err != nil {
+			utils.Log.Errorf("failed read manifest: %+v", err)
+			return
+		}
+		m, err := manifest.Parse(data)
+		if err != nil {
+			utils.Log.Errorf("%+v", err)
+			return
+		}
+		Init()
+		defer Release()
+		var changes []manifest.Change
+		if manifestDryRun {
+			changes, err = manifest.Plan(m, manifestPrune)
+		} else {
+			changes, err = manifest.Apply(context.Background(), m, manifestPrune, false)
+		}
+		for _, c := range changes {
+			if c.Action == manifest.None {
+				continue
+			}
+			utils.Log.Infof("%s %s", c.Action, c.MountPath)
+			for _, d := range c.Diffs {
+				utils.Log.Infof("  %s: %v -> %v", d.Field, d.Old, d.New)
+			}
+			if c.Error != "" {
+				utils.Log.Errorf("  failed: %s", c.Error)
+			}
+		}
+		if err != nil {
+			utils.Log.Errorf("%+v", err)
+			return
+		}
+		if manifestDryRun {
+			utils.Log.Infof("%d storages in the manifest, nothing is applied in dry run", len(m.Storages))
+		} else {
+			utils.Log.Infof("manifest is applied")
+		}
+	},
+}
+
 var baseStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.NormalBorder()).
 	BorderForeground(lipgloss.Color("240"))
@@ -150,6 +210,10 @@ func init() {
 	RootCmd.AddCommand(storageCmd)
 	storageCmd.AddCommand(disableStorageCmd)
 	storageCmd.AddCommand(listStorageCmd)
+	storageCmd.AddCommand(applyStorageCmd)
+	applyStorageCmd.Flags().StringVarP(&manifestFile, "file", "f", "", "manifest file in yaml or json")
+	applyStorageCmd.Flags().BoolVar(&manifestDryRun, "dry-run", false, "only show the changes")
+	applyStorageCmd.Flags().BoolVar(&manifestPrune, "prune", false, "delete the storages not in the manifest")
 	storageCmd.PersistentFlags().IntVarP(&storageTableHeight, "height", "H", 10, "Table height")
 	// Here you will define your flags and configuration settings.
 
@@ -248,4 +248,29 @@ func (d *Pan115) DeleteOfflineTasks(ctx context.Context, hashes []string, delete
 	return d.client.DeleteOfflineTasks(hashes, deleteFiles)
 }
 
+// SameAccount reports whether dst is a storage of the same 115 account
+func (d *Pan115) SameAccount(dst driver.Driver) bool {
+	s, ok := dst.(*Pan115)
+	return ok && d.client != nil && s.client != nil && d.client.UserID > 0 && d.client.UserID == s.client.UserID
+}
+
+func (d *Pan115) CopyTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Copy(ctx, srcObj, dstDir)
+}
+
+func (d *Pan115) MoveTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	if err := d.WaitLimit(ctx); err != nil {
+		return err
+	}
+	return d.client.Move(dstDir.GetID(), srcObj.GetID())
+}
+
+// RapidUploadHash returns sha1, which 115 checks besides the pre hash of the head
+func (d *Pan115) RapidUploadHash() []*utils.HashType {
+	return []*utils.HashType{utils.SHA1}
+}
+
 var _ driver.Driver = (*Pan115)(nil)
+var _ driver.CrossStorageCopier = (*Pan115)(nil)
+var _ driver.CrossStorageMover = (*Pan115)(nil)
+var _ driver.RapidUpload = (*Pan115)(nil)
@@ -359,3 +359,11 @@ func (y *Cloud189PC) Put(ctx context.Context, dstDir model.Obj, stream model.Fil
 		return y.StreamUpload(ctx, dstDir, stream, up, isFamily, overwrite)
 	}
 }
+
+// RapidUploadHash returns md5 if the rapid upload is enabled, which is slow to respond
+func (y *Cloud189PC) RapidUploadHash() []*utils.HashType {
+	if !y.Addition.RapidUpload {
+		return nil
+	}
+	return []*utils.HashType{utils.MD5}
+}
@@ -550,7 +550,7 @@ func (y *Cloud189PC) StreamUpload(ctx context.Context, dstDir model.Obj, file mo
 }
 
 func (y *Cloud189PC) RapidUpload(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, isFamily bool, overwrite bool) (model.Obj, error) {
-	fileMd5 := stream.GetHash().GetHash(utils.MD5)
+	fileMd5 := strings.ToLower(stream.GetHash().GetHash(utils.MD5))
 	if len(fileMd5) < utils.MD5.Width {
 		return nil, errors.New("invalid hash")
 	}
@@ -3,6 +3,7 @@ package alias
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -21,7 +22,9 @@ type Alias struct {
 }
 
 func (d *Alias) Config() driver.Config {
-	return config
+	c := config
+	c.NoUpload = !d.Writable
+	return c
 }
 
 func (d *Alias) GetAddition() driver.Additional {
@@ -50,6 +53,9 @@ func (d *Alias) Init(ctx context.Context) error {
 		d.oneKey = ""
 		d.autoFlatten = false
 	}
+	if d.spacePolicy() {
+		return d.checkSpaceInfo()
+	}
 	return nil
 }
 
@@ -71,7 +77,7 @@ func (d *Alias) Get(ctx context.Context, path string) (model.Obj, error) {
 	if !ok {
 		return nil, errs.ObjectNotFound
 	}
-	for _, dst := range dsts {
+	for _, dst := range d.readOrder(ctx, dsts, sub) {
 		obj, err := d.get(ctx, path, dst, sub)
 		if err == nil {
 			return obj, nil
@@ -98,6 +104,15 @@ func (d *Alias) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 			objs = append(objs, tmp...)
 		}
 	}
+	if d.Writable {
+		// same-name objects of the members are one in union mode
+		if d.ReadPolicy == PolicyNewest {
+			sort.SliceStable(objs, func(i, j int) bool {
+				return objs[i].ModTime().After(objs[j].ModTime())
+			})
+		}
+		objs = dedupe(objs)
+	}
 	return objs, nil
 }
 
@@ -107,7 +122,7 @@ func (d *Alias) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 	if !ok {
 		return nil, errs.ObjectNotFound
 	}
-	for _, dst := range dsts {
+	for _, dst := range d.readOrder(ctx, dsts, sub) {
 		link, err := d.link(ctx, dst, sub, args)
 		if err == nil {
 			return link, nil
@@ -117,6 +132,9 @@ func (d *Alias) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 }
 
 func (d *Alias) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	if d.Writable {
+		return d.unionRename(ctx, srcObj, newName)
+	}
 	reqPath, err := d.getReqPath(ctx, srcObj)
 	if err == nil {
 		return fs.Rename(ctx, *reqPath, newName)
@@ -128,6 +146,9 @@ func (d *Alias) Rename(ctx context.Context, srcObj model.Obj, newName string) er
 }
 
 func (d *Alias) Remove(ctx context.Context, obj model.Obj) error {
+	if d.Writable {
+		return d.unionRemove(ctx, obj)
+	}
 	reqPath, err := d.getReqPath(ctx, obj)
 	if err == nil {
 		return fs.Remove(ctx, *reqPath)
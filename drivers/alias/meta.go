@@ -11,8 +11,21 @@ type Addition struct {
 	// define other
 	Paths           string `json:"paths" required:"true" type:"text"`
 	ProtectSameName bool   `json:"protect_same_name" default:"true" required:"false" help:"Protects same-name files from Delete or Rename"`
+	Writable        bool   `json:"writable" help:"Union mode, supports upload, mkdir, move and copy by the policies"`
+	CreatePolicy    string `json:"create_policy" type:"select" options:"first_found,most_free_space,least_used" default:"first_found" help:"The path to create files and folders in union mode, most_free_space and least_used require all the storages of the paths to report their space"`
+	DeletePolicy    string `json:"delete_policy" type:"select" options:"all,first_found" default:"all" help:"The paths to delete, rename or move same-name objects in union mode"`
+	ReadPolicy      string `json:"read_policy" type:"select" options:"first_found,newest" default:"first_found" help:"The path to read same-name files from in union mode"`
 }
 
+// policies of the union mode
+const (
+	PolicyFirstFound    = "first_found"
+	PolicyMostFreeSpace = "most_free_space"
+	PolicyLeastUsed     = "least_used"
+	PolicyAll           = "all"
+	PolicyNewest        = "newest"
+)
+
 var config = driver.Config{
 	Name:             "Alias",
 	LocalSort:        true,
@@ -27,6 +40,9 @@ func init() {
 		return &Alias{
 			Addition: Addition{
 				ProtectSameName: true,
+				CreatePolicy:    PolicyFirstFound,
+				DeletePolicy:    PolicyAll,
+				ReadPolicy:      PolicyFirstFound,
 			},
 		}
 	})
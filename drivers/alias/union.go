@@ -0,0 +1,349 @@
+package alias
+
+import (
+	"context"
+	stdpath "path"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the union mode, the paths of a root are the members of the union
+
+func (d *Alias) getDsts(path string) (string, []string, error) {
+	root, sub := d.getRootAndPath(path)
+	dsts, ok := d.pathMap[root]
+	if !ok {
+		return "", nil, errs.ObjectNotFound
+	}
+	return sub, dsts, nil
+}
+
+// existing returns the members which have the sub path
+func (d *Alias) existing(ctx context.Context, dsts []string, sub string) []string {
+	var res []string
+	for _, dst := range dsts {
+		if _, err := fs.Get(ctx, stdpath.Join(dst, sub), &fs.GetArgs{NoLog: true}); err == nil {
+			res = append(res, dst)
+		}
+	}
+	return res
+}
+
+// readOrder returns the members in the order to read the sub path by the read policy
+func (d *Alias) readOrder(ctx context.Context, dsts []string, sub string) []string {
+	if d.ReadPolicy != PolicyNewest || len(dsts) < 2 {
+		return dsts
+	}
+	type member struct {
+		dst string
+		obj model.Obj
+	}
+	var members []member
+	for _, dst := range dsts {
+		obj, err := fs.Get(ctx, stdpath.Join(dst, sub), &fs.GetArgs{NoLog: true})
+		if err == nil {
+			members = append(members, member{dst: dst, obj: obj})
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].obj.ModTime().After(members[j].obj.ModTime())
+	})
+	res := make([]string, 0, len(members))
+	for _, m := range members {
+		res = append(res, m.dst)
+	}
+	return res
+}
+
+// deleteDsts returns the members to delete, rename or move the sub path by the delete policy
+func (d *Alias) deleteDsts(ctx context.Context, dsts []string, sub string) ([]string, error) {
+	if sub == "" {
+		return nil, errs.NotSupport
+	}
+	res := d.existing(ctx, dsts, sub)
+	if len(res) == 0 {
+		return nil, errs.ObjectNotFound
+	}
+	if d.DeletePolicy == PolicyFirstFound {
+		return res[:1], nil
+	}
+	return res, nil
+}
+
+// spacePolicy reports whether the create policy needs the space of the members
+func (d *Alias) spacePolicy() bool {
+	return d.Writable && (d.CreatePolicy == PolicyMostFreeSpace || d.CreatePolicy == PolicyLeastUsed)
+}
+
+// checkSpaceInfo returns an error if the storage of a member can't report its space,
+// the members whose storages are not loaded yet are skipped
+func (d *Alias) checkSpaceInfo() error {
+	for _, dsts := range d.pathMap {
+		for _, dst := range dsts {
+			storage, err := fs.GetStorage(dst, &fs.GetStoragesArgs{})
+			if err != nil {
+				continue
+			}
+			if _, ok := storage.(driver.SpaceInfo); !ok {
+				return errors.Errorf("storage of %s can't report its space, which is required by the create policy %s", dst, d.CreatePolicy)
+			}
+		}
+	}
+	return nil
+}
+
+// space returns the space of the member, nil without error if the storage fails to get it
+func space(ctx context.Context, dst string) (*model.Space, error) {
+	storage, err := fs.GetStorage(dst, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	s, ok := storage.(driver.SpaceInfo)
+	if !ok {
+		return nil, errors.Errorf("storage of %s can't report its space", dst)
+	}
+	res, err := s.GetSpace(ctx)
+	if err != nil {
+		log.Warnf("failed get space of %s: %+v", dst, err)
+		return nil, nil
+	}
+	return res, nil
+}
+
+// createDst returns the member to create objects in the dir by the create policy,
+// the members which have the dir are preferred, the ones failing to get the space are the last resort
+func (d *Alias) createDst(ctx context.Context, dsts []string, dirSub string) (string, error) {
+	candidates := d.existing(ctx, dsts, dirSub)
+	if len(candidates) == 0 {
+		candidates = dsts
+	}
+	if d.CreatePolicy != PolicyMostFreeSpace && d.CreatePolicy != PolicyLeastUsed {
+		return candidates[0], nil
+	}
+	best, bestSpace := candidates[0], (*model.Space)(nil)
+	for _, dst := range candidates {
+		s, err := space(ctx, dst)
+		if err != nil {
+			return "", errors.WithMessagef(err, "the create policy %s is not supported", d.CreatePolicy)
+		}
+		if s == nil {
+			continue
+		}
+		if bestSpace == nil ||
+			(d.CreatePolicy == PolicyMostFreeSpace && s.Free > bestSpace.Free) ||
+			(d.CreatePolicy == PolicyLeastUsed && s.Used < bestSpace.Used) {
+			best, bestSpace = dst, s
+		}
+	}
+	return best, nil
+}
+
+// dedupe keeps the first of the same-name objects, the members are in the read order
+func dedupe(objs []model.Obj) []model.Obj {
+	seen := make(map[string]struct{}, len(objs))
+	res := objs[:0]
+	for _, obj := range objs {
+		if _, ok := seen[obj.GetName()]; ok {
+			continue
+		}
+		seen[obj.GetName()] = struct{}{}
+		res = append(res, obj)
+	}
+	return res
+}
+
+func (d *Alias) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if !d.Writable {
+		return errs.NotImplement
+	}
+	sub, dsts, err := d.getDsts(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	dst, err := d.createDst(ctx, dsts, sub)
+	if err != nil {
+		return err
+	}
+	return fs.MakeDir(ctx, stdpath.Join(dst, sub, dirName))
+}
+
+func (d *Alias) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	if !d.Writable {
+		return errs.NotImplement
+	}
+	sub, dsts, err := d.getDsts(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	// an overwrite goes to the member served by the reads, the stale copies in the others are removed after it
+	fileSub := stdpath.Join(sub, stream.GetName())
+	holders := d.existing(ctx, d.readOrder(ctx, dsts, fileSub), fileSub)
+	var dst string
+	if len(holders) > 0 {
+		dst = holders[0]
+	} else if dst, err = d.createDst(ctx, dsts, sub); err != nil {
+		return err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(dst, sub))
+	if err != nil {
+		return err
+	}
+	if storage.Config().NoUpload {
+		return errors.WithStack(errs.UploadNotSupported)
+	}
+	if err := op.Put(ctx, storage, actualPath, stream, up); err != nil {
+		return err
+	}
+	for i := 1; i < len(holders); i++ {
+		if err := fs.Remove(ctx, stdpath.Join(holders[i], fileSub)); err != nil {
+			return errors.WithMessagef(err, "failed remove the stale copy in %s", holders[i])
+		}
+	}
+	return nil
+}
+
+// sameStorage returns the members on the storage of the path
+func sameStorage(dsts []string, path string) []string {
+	storage, err := fs.GetStorage(path, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, dst := range dsts {
+		if s, err := fs.GetStorage(dst, &fs.GetStoragesArgs{}); err == nil && s.GetStorage().MountPath == storage.GetStorage().MountPath {
+			res = append(res, dst)
+		}
+	}
+	return res
+}
+
+func (d *Alias) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.NotImplement
+	}
+	srcSub, srcDsts, err := d.getDsts(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dstSub, dstDsts, err := d.getDsts(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	srcs, err := d.deleteDsts(ctx, srcDsts, srcSub)
+	if err != nil {
+		return err
+	}
+	srcRoot, _ := d.getRootAndPath(srcObj.GetPath())
+	dstRoot, _ := d.getRootAndPath(dstDir.GetPath())
+	for _, src := range srcs {
+		// keep the object in its member if moved in the same root,
+		// otherwise prefer the members on the same storage, which can move it directly
+		dst, srcPath, cross := src, stdpath.Join(src, srcSub), false
+		if srcRoot != dstRoot {
+			candidates := sameStorage(dstDsts, srcPath)
+			if cross = len(candidates) == 0; cross {
+				// copy then delete between storages, folders are copied by tasks so they can't be deleted here
+				if srcObj.IsDir() {
+					return errors.WithStack(errs.MoveBetweenTwoStorages)
+				}
+				candidates = dstDsts
+			}
+			if dst, err = d.createDst(ctx, candidates, dstSub); err != nil {
+				return err
+			}
+		}
+		dstPath := stdpath.Join(dst, dstSub)
+		if err := fs.MakeDir(ctx, dstPath); err != nil {
+			return err
+		}
+		if !cross {
+			if err := fs.Move(ctx, srcPath, dstPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), srcPath, dstPath); err != nil {
+			return err
+		}
+		if err := fs.Remove(ctx, srcPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Alias) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if !d.Writable {
+		return errs.NotImplement
+	}
+	srcSub, srcDsts, err := d.getDsts(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dstSub, dstDsts, err := d.getDsts(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	srcs := d.existing(ctx, d.readOrder(ctx, srcDsts, srcSub), srcSub)
+	if len(srcs) == 0 {
+		return errs.ObjectNotFound
+	}
+	dst, err := d.createDst(ctx, dstDsts, dstSub)
+	if err != nil {
+		return err
+	}
+	dstPath := stdpath.Join(dst, dstSub)
+	if err := fs.MakeDir(ctx, dstPath); err != nil {
+		return err
+	}
+	// copy files between storages directly, folders are still copied by tasks
+	_, err = fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), stdpath.Join(srcs[0], srcSub), dstPath)
+	return err
+}
+
+func (d *Alias) unionRename(ctx context.Context, srcObj model.Obj, newName string) error {
+	sub, dsts, err := d.getDsts(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	targets, err := d.deleteDsts(ctx, dsts, sub)
+	if err != nil {
+		return err
+	}
+	for _, dst := range targets {
+		if err := fs.Rename(ctx, stdpath.Join(dst, sub), newName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Alias) unionRemove(ctx context.Context, obj model.Obj) error {
+	sub, dsts, err := d.getDsts(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	targets, err := d.deleteDsts(ctx, dsts, sub)
+	if err != nil {
+		return err
+	}
+	for _, dst := range targets {
+		if err := fs.Remove(ctx, stdpath.Join(dst, sub)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ driver.Mkdir = (*Alias)(nil)
+var _ driver.Put = (*Alias)(nil)
+var _ driver.Move = (*Alias)(nil)
+var _ driver.Copy = (*Alias)(nil)
@@ -220,8 +220,54 @@ func (d *AliyundriveOpen) Other(ctx context.Context, args model.OtherArgs) (inte
 	return resp, nil
 }
 
+// SameAccount reports whether dst is a storage of the same drive
+func (d *AliyundriveOpen) SameAccount(dst driver.Driver) bool {
+	s, ok := dst.(*AliyundriveOpen)
+	return ok && d.DriveId != "" && d.DriveId == s.DriveId
+}
+
+func (d *AliyundriveOpen) CopyTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Copy(ctx, srcObj, dstDir)
+}
+
+func (d *AliyundriveOpen) MoveTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	_, err := d.Move(ctx, srcObj, dstDir)
+	return err
+}
+
+func (d *AliyundriveOpen) RapidUploadHash() []*utils.HashType {
+	if !d.RapidUpload {
+		return nil
+	}
+	return []*utils.HashType{utils.SHA1}
+}
+
+// GetSpace returns the space of the personal drive
+func (d *AliyundriveOpen) GetSpace(ctx context.Context) (*model.Space, error) {
+	res, err := d.request("/adrive/v1.0/user/getSpaceInfo", http.MethodPost, func(req *resty.Request) {
+		req.SetContext(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	total := utils.Json.Get(res, "personal_space_info", "total_size").ToInt64()
+	used := utils.Json.Get(res, "personal_space_info", "used_size").ToInt64()
+	return &model.Space{Total: total, Used: used, Free: total - used}, nil
+}
+
+// HealthCheck checks the token by getting the space, cheaper than listing the root
+func (d *AliyundriveOpen) HealthCheck(ctx context.Context) error {
+	_, err := d.GetSpace(ctx)
+	return err
+}
+
 var _ driver.Driver = (*AliyundriveOpen)(nil)
 var _ driver.MkdirResult = (*AliyundriveOpen)(nil)
 var _ driver.MoveResult = (*AliyundriveOpen)(nil)
 var _ driver.RenameResult = (*AliyundriveOpen)(nil)
 var _ driver.PutResult = (*AliyundriveOpen)(nil)
+var _ driver.CrossStorageCopier = (*AliyundriveOpen)(nil)
+var _ driver.CrossStorageMover = (*AliyundriveOpen)(nil)
+var _ driver.RapidUpload = (*AliyundriveOpen)(nil)
+var _ driver.SpaceInfo = (*AliyundriveOpen)(nil)
+var _ driver.HealthChecker = (*AliyundriveOpen)(nil)
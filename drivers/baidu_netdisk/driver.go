@@ -7,9 +7,11 @@ import (
 	"errors"
 	"io"
 	"math"
+	"net/http"
 	"net/url"
 	stdpath "path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -19,6 +21,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/errgroup"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/avast/retry-go"
+	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -149,7 +152,7 @@ func (d *BaiduNetdisk) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *BaiduNetdisk) PutRapid(ctx context.Context, dstDir model.Obj, stream model.FileStreamer) (model.Obj, error) {
-	contentMd5 := stream.GetHash().GetHash(utils.MD5)
+	contentMd5 := strings.ToLower(stream.GetHash().GetHash(utils.MD5))
 	if len(contentMd5) < utils.MD5.Width {
 		return nil, errors.New("invalid hash")
 	}
@@ -329,4 +332,29 @@ func (d *BaiduNetdisk) uploadSlice(ctx context.Context, params map[string]string
 	return nil
 }
 
+func (d *BaiduNetdisk) RapidUploadHash() []*utils.HashType {
+	return []*utils.HashType{utils.MD5}
+}
+
+// GetSpace returns the quota of the account
+func (d *BaiduNetdisk) GetSpace(ctx context.Context) (*model.Space, error) {
+	var resp QuotaResp
+	_, err := d.request("https://pan.baidu.com/api/quota", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParams(map[string]string{"checkfree": "1", "checkexpire": "1"})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Space{Total: resp.Total, Used: resp.Used, Free: resp.Free}, nil
+}
+
+// HealthCheck checks the token by getting the space, cheaper than listing the root
+func (d *BaiduNetdisk) HealthCheck(ctx context.Context) error {
+	_, err := d.GetSpace(ctx)
+	return err
+}
+
 var _ driver.Driver = (*BaiduNetdisk)(nil)
+var _ driver.RapidUpload = (*BaiduNetdisk)(nil)
+var _ driver.SpaceInfo = (*BaiduNetdisk)(nil)
+var _ driver.HealthChecker = (*BaiduNetdisk)(nil)
@@ -0,0 +1,275 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Chunker stores the files in the remote path, a file larger than the chunk size
+// is split into chunks and a metadata file, which are merged back when listing
+type Chunker struct {
+	model.Storage
+	Addition
+	chunkSize int64
+}
+
+func (d *Chunker) Config() driver.Config {
+	return config
+}
+
+func (d *Chunker) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Chunker) Init(ctx context.Context) error {
+	if d.ChunkSize <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+	d.chunkSize = d.ChunkSize * utils.MB
+	if utils.IsSubPath(d.MountPath, d.RemotePath) {
+		return errors.New("remote path can't be in the chunker itself")
+	}
+	//need remote storage exist
+	_, _, err := d.getRemote()
+	return err
+}
+
+func (d *Chunker) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Chunker) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	objs, err := d.list(ctx, storage, root, stdpath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	name := stdpath.Base(path)
+	for _, obj := range objs {
+		if obj.Name == name {
+			return obj, nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Chunker) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	objs, err := d.list(ctx, storage, root, dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(src *Object) (model.Obj, error) {
+		return src, nil
+	})
+}
+
+func (d *Chunker) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	obj, ok := file.(*Object)
+	if !ok {
+		return nil, errs.NotSupport
+	}
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	dir := stdpath.Join(root, stdpath.Dir(obj.GetPath()))
+	// the parts are read as they are, not the thumbnails
+	args.Type = ""
+	rrc := &model.RangeReadCloser{Closers: utils.EmptyClosers()}
+	rrc.RangeReader = func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		if httpRange.Length < 0 || httpRange.Start+httpRange.Length > obj.GetSize() {
+			httpRange.Length = obj.GetSize() - httpRange.Start
+		}
+		return newPartsReader(obj.parts, httpRange, func(p partRange) (io.ReadCloser, error) {
+			return op.OpenRange(ctx, storage, stdpath.Join(dir, p.Name), p.Size, p.Range, args, &rrc.Closers)
+		}), nil
+	}
+	return &model.Link{RangeReadCloser: rrc}, nil
+}
+
+func (d *Chunker) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, stdpath.Join(root, parentDir.GetPath(), dirName))
+}
+
+func (d *Chunker) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	srcDir := stdpath.Join(root, stdpath.Dir(srcObj.GetPath()))
+	for _, name := range srcObj.(*Object).names() {
+		if err := op.Move(ctx, storage, stdpath.Join(srcDir, name), stdpath.Join(root, dstDir.GetPath())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	obj := srcObj.(*Object)
+	srcDir := stdpath.Join(root, stdpath.Dir(obj.GetPath()))
+	for _, name := range obj.names() {
+		if err := op.Rename(ctx, storage, stdpath.Join(srcDir, name), obj.rename(name, newName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	srcDir := stdpath.Join(root, stdpath.Dir(srcObj.GetPath()))
+	for _, name := range srcObj.(*Object).names() {
+		if err := op.Copy(ctx, storage, stdpath.Join(srcDir, name), stdpath.Join(root, dstDir.GetPath())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Remove(ctx context.Context, obj model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	dir := stdpath.Join(root, stdpath.Dir(obj.GetPath()))
+	// the metadata file is removed first to hide the file
+	names := obj.(*Object).names()
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := op.Remove(ctx, storage, stdpath.Join(dir, names[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Chunker) Put(ctx context.Context, dstDir model.Obj, streamer model.FileStreamer, up driver.UpdateProgress) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	dir := stdpath.Join(root, dstDir.GetPath())
+	name, size := streamer.GetName(), streamer.GetSize()
+	// the existing file is renamed before the upload, what's left are the chunks of failed uploads
+	if err := d.removeChunks(ctx, storage, dir, name); err != nil {
+		return err
+	}
+	if size <= d.chunkSize {
+		s := &stream.FileStream{
+			Obj: &model.Object{
+				Name:     name,
+				Size:     size,
+				Modified: streamer.ModTime(),
+				Ctime:    streamer.CreateTime(),
+			},
+			Reader:       streamer,
+			Mimetype:     streamer.GetMimetype(),
+			WebPutAsTask: streamer.NeedStore(),
+			HashInfo:     streamer.GetHash(),
+		}
+		return op.Put(ctx, storage, dir, s, up, false)
+	}
+	chunks := int((size + d.chunkSize - 1) / d.chunkSize)
+	for i := 0; i < chunks; i++ {
+		offset := int64(i) * d.chunkSize
+		chunkSize := min(d.chunkSize, size-offset)
+		s := &stream.FileStream{
+			Obj: &model.Object{
+				Name:     chunkName(name, i),
+				Size:     chunkSize,
+				Modified: streamer.ModTime(),
+				Ctime:    streamer.CreateTime(),
+			},
+			Reader:            io.LimitReader(streamer, chunkSize),
+			Mimetype:          "application/octet-stream",
+			WebPutAsTask:      streamer.NeedStore(),
+			ForceStreamUpload: true,
+		}
+		err = op.Put(ctx, storage, dir, s, func(p float64) {
+			up((float64(offset) + p/100*float64(chunkSize)) * 100 / float64(size))
+		}, false)
+		if err != nil {
+			if err := d.removeChunks(ctx, storage, dir, name); err != nil {
+				log.Errorf("chunker: failed remove the chunks of %s: %+v", name, err)
+			}
+			return errors.WithMessagef(err, "failed upload chunk %d of %s", i+1, name)
+		}
+	}
+	data, err := json.Marshal(chunkMeta{Version: metaVersion, Size: size, ChunkSize: d.chunkSize, Chunks: chunks})
+	if err != nil {
+		return err
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     metaName(name),
+			Size:     int64(len(data)),
+			Modified: streamer.ModTime(),
+		},
+		Reader:   bytes.NewReader(data),
+		Mimetype: "application/json",
+	}
+	return op.Put(ctx, storage, dir, s, nil, false)
+}
+
+// removeChunks removes the remote chunks and metadata file of the name in the dir
+func (d *Chunker) removeChunks(ctx context.Context, storage driver.Driver, dir, name string) error {
+	objs, err := op.List(ctx, storage, dir, model.ListArgs{})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	for _, obj := range objs {
+		if obj.IsDir() {
+			continue
+		}
+		if base, _, ok := parseChunkName(obj.GetName()); !(ok && base == name) && obj.GetName() != metaName(name) {
+			continue
+		}
+		if err := op.Remove(ctx, storage, stdpath.Join(dir, obj.GetName())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ driver.Driver = (*Chunker)(nil)
@@ -0,0 +1,91 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+func init() {
+	drivertest.InitDB()
+}
+
+// newChunker returns a chunker with chunks of 1000 bytes over a local storage and the folder of the local storage
+func newChunker(t *testing.T) (*Chunker, string) {
+	root := drivertest.NewLocalStorage(t, "/chunker_remote")
+	d := &Chunker{Addition: Addition{RemotePath: "/chunker_remote", ChunkSize: 1}}
+	drivertest.Init(t, d)
+	d.chunkSize = 1000
+	return d, root
+}
+
+func TestConformance(t *testing.T) {
+	d, _ := newChunker(t)
+	drivertest.Run(t, d, drivertest.Options{})
+}
+
+func TestChunks(t *testing.T) {
+	ctx := context.Background()
+	d, root := newChunker(t)
+	data := drivertest.Content(2500, 1)
+	if err := drivertest.Put(ctx, d, "/", "a.bin", data); err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	if err := drivertest.Put(ctx, d, "/", "b.txt", []byte("alist")); err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	expected := []string{"a.bin.alist_chunk.001", "a.bin.alist_chunk.002", "a.bin.alist_chunk.003", "a.bin.alist_chunk.meta", "b.txt"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Fatalf("remote files: expected %v, got %v", expected, names)
+	}
+
+	objs, err := drivertest.List(ctx, d, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs["a.bin"] == nil || objs["a.bin"].GetSize() != 2500 {
+		t.Fatalf("expected a.bin of 2500 bytes and b.txt, got %v", objs)
+	}
+	got, err := drivertest.Read(ctx, d, "/a.bin", http_range.Range{Start: 900, Length: 1200})
+	if err != nil {
+		t.Fatalf("failed read: %+v", err)
+	}
+	if !bytes.Equal(got, data[900:2100]) {
+		t.Error("content across the chunks mismatch")
+	}
+
+	// without the last chunk the file is truncated
+	if err := os.Rename(filepath.Join(root, "a.bin.alist_chunk.003"), filepath.Join(root, "a.bin.003")); err != nil {
+		t.Fatal(err)
+	}
+	if objs, _ := drivertest.List(ctx, d, "/"); objs["a.bin"] != nil {
+		t.Error("truncated a.bin is listed")
+	}
+	if err := os.Rename(filepath.Join(root, "a.bin.003"), filepath.Join(root, "a.bin.alist_chunk.003")); err != nil {
+		t.Fatal(err)
+	}
+	// without the metadata file the upload is incomplete
+	if err := os.Remove(filepath.Join(root, "a.bin.alist_chunk.meta")); err != nil {
+		t.Fatal(err)
+	}
+	if objs, _ := drivertest.List(ctx, d, "/"); objs["a.bin"] != nil {
+		t.Error("incomplete a.bin is listed")
+	}
+}
@@ -0,0 +1,31 @@
+package chunker
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"This is where the chunks store"`
+	ChunkSize  int64  `json:"chunk_size" type:"number" required:"true" default:"100" help:"The size of chunks in MB, files not larger than it are stored as they are"`
+}
+
+var config = driver.Config{
+	Name:        "Chunker",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+	// the old file is kept until the chunks of the new one are all uploaded
+	NoOverwriteUpload: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Chunker{
+			Addition: Addition{
+				ChunkSize: 100,
+			},
+		}
+	})
+}
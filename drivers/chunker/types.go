@@ -0,0 +1,27 @@
+package chunker
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+// part is a remote file holding the data of a file,
+// a file which is not chunked has itself as the only part
+type part struct {
+	Name string
+	Size int64
+}
+
+// Object is a file or folder of the chunker, with the remote parts of the file
+type Object struct {
+	model.Object
+	parts []part
+	// meta is the name of the metadata file if the file is chunked
+	meta string
+}
+
+// chunkMeta is written to the metadata file after all the chunks are uploaded,
+// so a file is not listed until its upload is complete
+type chunkMeta struct {
+	Version   int   `json:"version"`
+	Size      int64 `json:"size"`
+	ChunkSize int64 `json:"chunk_size"`
+	Chunks    int   `json:"chunks"`
+}
@@ -0,0 +1,238 @@
+package chunker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the chunks of a file are named <name>.alist_chunk.001, <name>.alist_chunk.002 ...
+// and the metadata file is named <name>.alist_chunk.meta
+const (
+	chunkInfix  = ".alist_chunk."
+	metaSuffix  = chunkInfix + "meta"
+	metaVersion = 1
+)
+
+// chunkName returns the name of the i-th (from 0) chunk of the file
+func chunkName(name string, i int) string {
+	return fmt.Sprintf("%s%s%03d", name, chunkInfix, i+1)
+}
+
+func metaName(name string) string {
+	return name + metaSuffix
+}
+
+// parseChunkName returns the file name and the index (from 0) of a chunk
+func parseChunkName(name string) (string, int, bool) {
+	i := strings.LastIndex(name, chunkInfix)
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(name[i+len(chunkInfix):])
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return name[:i], n - 1, true
+}
+
+// metaCache caches the metadata files by their paths, sizes and modified times
+var metaCache = cache.NewMemCache(cache.WithShards[*chunkMeta](16))
+
+// readMeta reads the metadata file of obj in the remote path
+func readMeta(ctx context.Context, storage driver.Driver, path string, obj model.Obj) (*chunkMeta, error) {
+	key := fmt.Sprintf("%s|%d|%d", stdpath.Join(storage.GetStorage().MountPath, path), obj.GetSize(), obj.ModTime().UnixNano())
+	if meta, ok := metaCache.Get(key); ok {
+		return meta, nil
+	}
+	closers := utils.EmptyClosers()
+	defer closers.Close()
+	rc, err := op.OpenRange(ctx, storage, path, obj.GetSize(), http_range.Range{Length: obj.GetSize()}, model.LinkArgs{}, &closers)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var meta chunkMeta
+	if err := utils.Json.NewDecoder(io.LimitReader(rc, obj.GetSize())).Decode(&meta); err != nil {
+		return nil, errors.Wrap(err, "invalid metadata")
+	}
+	if meta.Version != metaVersion {
+		return nil, errors.Errorf("unsupported metadata version %d", meta.Version)
+	}
+	metaCache.Set(key, &meta, cache.WithEx[*chunkMeta](time.Hour))
+	return &meta, nil
+}
+
+// getRemote returns the remote storage and the actual path of the remote path in it
+func (d *Chunker) getRemote() (driver.Driver, string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(d.RemotePath)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "can't find remote storage")
+	}
+	return storage, actualPath, nil
+}
+
+// list lists the remote dir, the chunks are merged into their files and the incomplete ones are hidden,
+// a file is complete if the chunks match the count and the size in its metadata file
+func (d *Chunker) list(ctx context.Context, storage driver.Driver, root, dir string) ([]*Object, error) {
+	objs, err := op.List(ctx, storage, stdpath.Join(root, dir), model.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	chunks := make(map[string]map[int]model.Obj)
+	for _, obj := range objs {
+		if obj.IsDir() {
+			continue
+		}
+		if name, i, ok := parseChunkName(obj.GetName()); ok {
+			if chunks[name] == nil {
+				chunks[name] = make(map[int]model.Obj)
+			}
+			chunks[name][i] = obj
+		}
+	}
+	res := make([]*Object, 0, len(objs))
+	for _, obj := range objs {
+		name := obj.GetName()
+		o := &Object{Object: model.Object{
+			Path:     stdpath.Join(dir, name),
+			Name:     name,
+			Modified: obj.ModTime(),
+			Ctime:    obj.CreateTime(),
+			IsFolder: obj.IsDir(),
+		}}
+		if obj.IsDir() {
+			res = append(res, o)
+			continue
+		}
+		if _, _, ok := parseChunkName(name); ok {
+			continue
+		}
+		if base, ok := strings.CutSuffix(name, metaSuffix); ok {
+			o.Name, o.Path, o.meta = base, stdpath.Join(dir, base), name
+			meta, err := readMeta(ctx, storage, stdpath.Join(root, dir, name), obj)
+			if err != nil {
+				log.Warnf("chunker: failed read the metadata of %s: %+v", o.Path, err)
+				continue
+			}
+			for i := 0; i < meta.Chunks; i++ {
+				chunk, ok := chunks[base][i]
+				if !ok {
+					break
+				}
+				o.parts = append(o.parts, part{Name: chunk.GetName(), Size: chunk.GetSize()})
+				o.Size += chunk.GetSize()
+			}
+			if len(o.parts) == 0 || len(o.parts) != meta.Chunks || o.Size != meta.Size {
+				log.Warnf("chunker: the chunks of %s are incomplete", o.Path)
+				continue
+			}
+			res = append(res, o)
+			continue
+		}
+		o.Size = obj.GetSize()
+		o.HashInfo = obj.GetHash()
+		o.parts = []part{{Name: name, Size: obj.GetSize()}}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+// names returns the remote names of the object, the metadata file goes last,
+// so a copied or moved file is not listed until all its chunks are in place
+func (o *Object) names() []string {
+	if o.IsDir() || o.meta == "" {
+		return []string{o.Name}
+	}
+	var names []string
+	for _, p := range o.parts {
+		names = append(names, p.Name)
+	}
+	return append(names, o.meta)
+}
+
+// rename returns the new remote name of a name of the object
+func (o *Object) rename(name, newName string) string {
+	if name == o.Name {
+		return newName
+	}
+	if name == o.meta {
+		return metaName(newName)
+	}
+	_, i, _ := parseChunkName(name)
+	return chunkName(newName, i)
+}
+
+type partRange struct {
+	part
+	http_range.Range
+}
+
+// partsReader reads the ranges of the parts one by one, a part is opened when it's reached
+type partsReader struct {
+	ranges []partRange
+	open   func(p partRange) (io.ReadCloser, error)
+	cur    io.ReadCloser
+}
+
+func newPartsReader(parts []part, r http_range.Range, open func(p partRange) (io.ReadCloser, error)) *partsReader {
+	var ranges []partRange
+	var offset int64
+	end := r.Start + r.Length
+	for _, p := range parts {
+		start, stop := max(r.Start, offset), min(end, offset+p.Size)
+		if start < stop {
+			ranges = append(ranges, partRange{part: p, Range: http_range.Range{Start: start - offset, Length: stop - start}})
+		}
+		offset += p.Size
+	}
+	return &partsReader{ranges: ranges, open: open}
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.ranges) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := r.open(r.ranges[0])
+			if err != nil {
+				return 0, err
+			}
+			r.cur, r.ranges = rc, r.ranges[1:]
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			err = r.cur.Close()
+			r.cur = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	return err
+}
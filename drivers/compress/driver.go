@@ -0,0 +1,268 @@
+package compress
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Compress stores the files compressed in the remote path, except the already compressed videos and images
+type Compress struct {
+	model.Storage
+	Addition
+}
+
+func (d *Compress) Config() driver.Config {
+	return config
+}
+
+func (d *Compress) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Compress) Init(ctx context.Context) error {
+	if _, ok := codecs[d.Algorithm]; !ok {
+		return errors.Errorf("unsupported algorithm: %s", d.Algorithm)
+	}
+	if utils.IsSubPath(d.MountPath, d.RemotePath) {
+		return errors.New("remote path can't be in the compress itself")
+	}
+	//need remote storage exist
+	_, _, err := d.getRemote()
+	return err
+}
+
+func (d *Compress) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Compress) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	objs, err := d.list(ctx, storage, root, stdpath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	name := stdpath.Base(path)
+	for _, obj := range objs {
+		if obj.Name == name {
+			return obj, nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (d *Compress) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	objs, err := d.list(ctx, storage, root, dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(src *Object) (model.Obj, error) {
+		return src, nil
+	})
+}
+
+func (d *Compress) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	obj, ok := file.(*Object)
+	if !ok {
+		return nil, errs.NotSupport
+	}
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return nil, err
+	}
+	path := stdpath.Join(root, stdpath.Dir(obj.GetPath()), obj.remoteName)
+	// the remote file is read as it is, not the thumbnail
+	args.Type = ""
+	rrc := &model.RangeReadCloser{Closers: utils.EmptyClosers()}
+	open := func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+		return op.OpenRange(ctx, storage, path, obj.remoteSize, r, args, &rrc.Closers)
+	}
+	if obj.algorithm == "" {
+		rrc.RangeReader = func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+			if httpRange.Length < 0 || httpRange.Start+httpRange.Length > obj.GetSize() {
+				httpRange.Length = obj.GetSize() - httpRange.Start
+			}
+			return open(ctx, httpRange)
+		}
+		return &model.Link{RangeReadCloser: rrc}, nil
+	}
+
+	tableSize := seekTableSize(int((obj.GetSize() + blockSize - 1) / blockSize))
+	if obj.remoteSize < tableSize {
+		return nil, errors.Errorf("remote file of %s is broken", obj.GetName())
+	}
+	rc, err := open(ctx, http_range.Range{Start: obj.remoteSize - tableSize, Length: tableSize})
+	if err != nil {
+		return nil, err
+	}
+	table, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	frames, err := parseSeekTable(table, obj.GetSize())
+	if err != nil {
+		return nil, errors.WithMessagef(err, "remote file of %s is broken", obj.GetName())
+	}
+	rrc.RangeReader = func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+		if httpRange.Length < 0 || httpRange.Start+httpRange.Length > obj.GetSize() {
+			httpRange.Length = obj.GetSize() - httpRange.Start
+		}
+		return newFramesReader(frames, httpRange, codecs[obj.algorithm], func(r http_range.Range) (io.ReadCloser, error) {
+			return open(ctx, r)
+		}), nil
+	}
+	return &model.Link{RangeReadCloser: rrc}, nil
+}
+
+func (d *Compress) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, stdpath.Join(root, parentDir.GetPath(), dirName))
+}
+
+func (d *Compress) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	srcPath := stdpath.Join(root, stdpath.Dir(srcObj.GetPath()), srcObj.(*Object).remoteName)
+	return op.Move(ctx, storage, srcPath, stdpath.Join(root, dstDir.GetPath()))
+}
+
+func (d *Compress) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	obj := srcObj.(*Object)
+	srcPath := stdpath.Join(root, stdpath.Dir(obj.GetPath()), obj.remoteName)
+	return op.Rename(ctx, storage, srcPath, obj.rename(newName))
+}
+
+func (d *Compress) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	srcPath := stdpath.Join(root, stdpath.Dir(srcObj.GetPath()), srcObj.(*Object).remoteName)
+	return op.Copy(ctx, storage, srcPath, stdpath.Join(root, dstDir.GetPath()))
+}
+
+func (d *Compress) Remove(ctx context.Context, obj model.Obj) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, stdpath.Join(root, stdpath.Dir(obj.GetPath()), obj.(*Object).remoteName))
+}
+
+func (d *Compress) Put(ctx context.Context, dstDir model.Obj, streamer model.FileStreamer, up driver.UpdateProgress) error {
+	storage, root, err := d.getRemote()
+	if err != nil {
+		return err
+	}
+	dir := stdpath.Join(root, dstDir.GetPath())
+	name, size := streamer.GetName(), streamer.GetSize()
+	if skipCompress(name) {
+		s := &stream.FileStream{
+			Obj: &model.Object{
+				Name:     name,
+				Size:     size,
+				Modified: streamer.ModTime(),
+				Ctime:    streamer.CreateTime(),
+			},
+			Reader:       streamer,
+			Mimetype:     streamer.GetMimetype(),
+			WebPutAsTask: streamer.NeedStore(),
+			HashInfo:     streamer.GetHash(),
+		}
+		return op.Put(ctx, storage, dir, s, up, false)
+	}
+
+	// the compressed size is unknown until the whole file is compressed
+	tmpF, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmpF.Close()
+		_ = os.Remove(tmpF.Name())
+	}()
+	c := codecs[d.Algorithm]
+	block := make([]byte, blockSize)
+	var frames []frame
+	var read int64
+	for read < size {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		n, err := io.ReadFull(streamer, block[:min(blockSize, size-read)])
+		if err != nil {
+			return err
+		}
+		compressed, err := c.compress(block[:n])
+		if err != nil {
+			return err
+		}
+		if _, err = tmpF.Write(compressed); err != nil {
+			return err
+		}
+		frames = append(frames, frame{cSize: uint32(len(compressed)), dSize: uint32(n)})
+		read += int64(n)
+		up(float64(read) / float64(size) * 50)
+	}
+	if _, err = tmpF.Write(appendSeekTable(nil, frames)); err != nil {
+		return err
+	}
+	compressedSize, err := tmpF.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = tmpF.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     remoteName(name, size, d.Algorithm),
+			Size:     compressedSize,
+			Modified: streamer.ModTime(),
+			Ctime:    streamer.CreateTime(),
+		},
+		Reader:       tmpF,
+		Mimetype:     "application/octet-stream",
+		WebPutAsTask: streamer.NeedStore(),
+	}
+	return op.Put(ctx, storage, dir, s, func(p float64) {
+		up(50 + p/2)
+	}, false)
+}
+
+var _ driver.Driver = (*Compress)(nil)
@@ -0,0 +1,94 @@
+package compress_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/drivers/compress"
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	drivertest.InitDB()
+	conf.SlicesMap[conf.VideoTypes] = []string{"mp4"}
+}
+
+// newCompress returns a compress driver over a local storage and the folder of the local storage
+func newCompress(t *testing.T, algorithm string) (*compress.Compress, string) {
+	root := drivertest.NewLocalStorage(t, "/compress_remote")
+	d := &compress.Compress{Addition: compress.Addition{RemotePath: "/compress_remote", Algorithm: algorithm}}
+	drivertest.Init(t, d)
+	return d, root
+}
+
+func TestConformance(t *testing.T) {
+	d, _ := newCompress(t, "zstd")
+	drivertest.Run(t, d, drivertest.Options{})
+}
+
+func TestCompress(t *testing.T) {
+	ctx := context.Background()
+	// compressible and larger than a block
+	data := bytes.Repeat([]byte("2024-01-01 00:00:00 INFO alist started\n"), 80000)
+	for _, algorithm := range []string{"zstd", "gzip"} {
+		t.Run(algorithm, func(t *testing.T) {
+			d, root := newCompress(t, algorithm)
+			if err := drivertest.Put(ctx, d, "/", "a.log", data); err != nil {
+				t.Fatalf("failed put: %+v", err)
+			}
+			remote, err := os.ReadFile(filepath.Join(root, fmt.Sprintf("a.log.%d.alist_%s", len(data), algorithm)))
+			if err != nil {
+				t.Fatalf("compressed file not found: %v", err)
+			}
+			if len(remote) >= len(data)/10 {
+				t.Errorf("compressed to %d bytes from %d", len(remote), len(data))
+			}
+			if algorithm == "zstd" {
+				// the seek table is skipped by the zstd decoders
+				dec, _ := zstd.NewReader(nil)
+				got, err := dec.DecodeAll(remote, nil)
+				dec.Close()
+				if err != nil || !bytes.Equal(got, data) {
+					t.Errorf("not decompressed by zstd: %v", err)
+				}
+			}
+
+			objs, err := drivertest.List(ctx, d, "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj := objs["a.log"]; obj == nil || obj.GetSize() != int64(len(data)) {
+				t.Fatalf("expected a.log of %d bytes, got %v", len(data), objs)
+			}
+			r := http_range.Range{Start: 1000000, Length: 1500000}
+			got, err := drivertest.Read(ctx, d, "/a.log", r)
+			if err != nil {
+				t.Fatalf("failed read: %+v", err)
+			}
+			if !bytes.Equal(got, data[r.Start:r.Start+r.Length]) {
+				t.Error("content across the frames mismatch")
+			}
+		})
+	}
+}
+
+func TestSkipCompress(t *testing.T) {
+	ctx := context.Background()
+	d, root := newCompress(t, "zstd")
+	data := drivertest.Content(4096, 1)
+	if err := drivertest.Put(ctx, d, "/", "v.mp4", data); err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	remote, err := os.ReadFile(filepath.Join(root, "v.mp4"))
+	if err != nil || !bytes.Equal(remote, data) {
+		t.Fatalf("video is expected to be stored as it is: %v", err)
+	}
+}
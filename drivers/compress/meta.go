@@ -0,0 +1,31 @@
+package compress
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"This is where the compressed data stores"`
+	Algorithm  string `json:"algorithm" type:"select" required:"true" options:"zstd,gzip" default:"zstd" help:"The algorithm to compress new files, the existing files keep theirs"`
+}
+
+var config = driver.Config{
+	Name:        "Compress",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+	// the size is in the remote name, so the old file is removed after the upload
+	NoOverwriteUpload: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Compress{
+			Addition: Addition{
+				Algorithm: "zstd",
+			},
+		}
+	})
+}
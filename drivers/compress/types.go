@@ -0,0 +1,20 @@
+package compress
+
+import "github.com/alist-org/alist/v3/internal/model"
+
+// Object is a file or folder of the compress driver, the size is the original one
+type Object struct {
+	model.Object
+	remoteName string
+	remoteSize int64
+	// algorithm is empty if the file is stored as it is
+	algorithm string
+}
+
+// frame is an independently compressed block of a file
+type frame struct {
+	offset int64 // offset of the compressed frame in the remote file
+	start  int64 // offset of the block in the original file
+	cSize  uint32
+	dSize  uint32
+}
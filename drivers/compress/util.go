@@ -0,0 +1,282 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// a compressed file is named <name>.<original size>.alist_<algorithm>, it consists of the frames
+// of the blocks compressed independently and a seek table at the end in the format of zstd seekable
+// https://github.com/facebook/zstd/blob/dev/contrib/seekable_format/zstd_seekable_compression_format.md,
+// so a zstd one can still be decompressed by the zstd tools
+const (
+	suffixPrefix = ".alist_"
+	blockSize    = utils.MB
+
+	skippableMagic  = 0x184D2A5E
+	seekableMagic   = 0x8F92EAB1
+	frameHeaderSize = 8
+	entrySize       = 8
+	footerSize      = 9
+)
+
+type codec struct {
+	compress   func(src []byte) ([]byte, error)
+	decompress func(src []byte) ([]byte, error)
+}
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		e, _ := zstd.NewWriter(nil)
+		return e
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		d, _ := zstd.NewReader(nil)
+		return d
+	})
+)
+
+var codecs = map[string]codec{
+	"zstd": {
+		compress: func(src []byte) ([]byte, error) {
+			return zstdEncoder().EncodeAll(src, nil), nil
+		},
+		decompress: func(src []byte) ([]byte, error) {
+			return zstdDecoder().DecodeAll(src, nil)
+		},
+	},
+	"gzip": {
+		compress: func(src []byte) ([]byte, error) {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			if _, err := w.Write(src); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		decompress: func(src []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(src))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(r)
+		},
+	},
+}
+
+// skipCompress reports whether the file is already compressed by its type
+func skipCompress(name string) bool {
+	t := utils.GetFileType(name)
+	return t == conf.VIDEO || t == conf.IMAGE
+}
+
+func remoteName(name string, size int64, algorithm string) string {
+	return fmt.Sprintf("%s.%d%s%s", name, size, suffixPrefix, algorithm)
+}
+
+// parseRemoteName returns the original name, size and the algorithm of a compressed file
+func parseRemoteName(name string) (string, int64, string, bool) {
+	i := strings.LastIndex(name, suffixPrefix)
+	if i < 0 {
+		return "", 0, "", false
+	}
+	algorithm := name[i+len(suffixPrefix):]
+	if _, ok := codecs[algorithm]; !ok {
+		return "", 0, "", false
+	}
+	j := strings.LastIndex(name[:i], ".")
+	if j < 0 {
+		return "", 0, "", false
+	}
+	size, err := strconv.ParseInt(name[j+1:i], 10, 64)
+	if err != nil || size < 0 {
+		return "", 0, "", false
+	}
+	return name[:j], size, algorithm, true
+}
+
+func (o *Object) rename(newName string) string {
+	if o.algorithm == "" {
+		return newName
+	}
+	return remoteName(newName, o.Size, o.algorithm)
+}
+
+func seekTableSize(frames int) int64 {
+	return int64(frameHeaderSize + entrySize*frames + footerSize)
+}
+
+func appendSeekTable(b []byte, frames []frame) []byte {
+	b = binary.LittleEndian.AppendUint32(b, skippableMagic)
+	b = binary.LittleEndian.AppendUint32(b, uint32(entrySize*len(frames)+footerSize))
+	for _, f := range frames {
+		b = binary.LittleEndian.AppendUint32(b, f.cSize)
+		b = binary.LittleEndian.AppendUint32(b, f.dSize)
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(frames)))
+	// no checksums
+	b = append(b, 0)
+	return binary.LittleEndian.AppendUint32(b, seekableMagic)
+}
+
+func parseSeekTable(b []byte, size int64) ([]frame, error) {
+	if len(b) < frameHeaderSize+footerSize || binary.LittleEndian.Uint32(b) != skippableMagic ||
+		binary.LittleEndian.Uint32(b[len(b)-4:]) != seekableMagic {
+		return nil, errors.New("invalid seek table")
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-footerSize:]))
+	if seekTableSize(n) != int64(len(b)) || b[len(b)-5] != 0 {
+		return nil, errors.New("invalid seek table")
+	}
+	frames := make([]frame, n)
+	var offset, start int64
+	for i := range frames {
+		entry := b[frameHeaderSize+entrySize*i:]
+		frames[i] = frame{
+			offset: offset,
+			start:  start,
+			cSize:  binary.LittleEndian.Uint32(entry),
+			dSize:  binary.LittleEndian.Uint32(entry[4:]),
+		}
+		offset += int64(frames[i].cSize)
+		start += int64(frames[i].dSize)
+	}
+	if start != size {
+		return nil, errors.Errorf("size in seek table %d mismatches %d", start, size)
+	}
+	return frames, nil
+}
+
+// getRemote returns the remote storage and the actual path of the remote path in it
+func (d *Compress) getRemote() (driver.Driver, string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(d.RemotePath)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "can't find remote storage")
+	}
+	return storage, actualPath, nil
+}
+
+// list lists the remote dir with the original names and sizes
+func (d *Compress) list(ctx context.Context, storage driver.Driver, root, dir string) ([]*Object, error) {
+	objs, err := op.List(ctx, storage, stdpath.Join(root, dir), model.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Object, 0, len(objs))
+	for _, obj := range objs {
+		o := &Object{
+			Object: model.Object{
+				Path:     stdpath.Join(dir, obj.GetName()),
+				Name:     obj.GetName(),
+				Size:     obj.GetSize(),
+				Modified: obj.ModTime(),
+				Ctime:    obj.CreateTime(),
+				IsFolder: obj.IsDir(),
+			},
+			remoteName: obj.GetName(),
+			remoteSize: obj.GetSize(),
+		}
+		if obj.IsDir() {
+			o.Size = 0
+		} else if name, size, algorithm, ok := parseRemoteName(obj.GetName()); ok {
+			o.Name, o.Path, o.Size, o.algorithm = name, stdpath.Join(dir, name), size, algorithm
+		} else {
+			o.HashInfo = obj.GetHash()
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+// framesReader decompresses the frames covering a range of the original file,
+// the compressed frames are read from the remote in a single range
+type framesReader struct {
+	frames     []frame
+	start, end int64
+	decompress func(src []byte) ([]byte, error)
+	open       func(r http_range.Range) (io.ReadCloser, error)
+	remote     io.ReadCloser
+	buf        []byte
+}
+
+func newFramesReader(frames []frame, r http_range.Range, c codec, open func(r http_range.Range) (io.ReadCloser, error)) *framesReader {
+	end := r.Start + r.Length
+	first, last := len(frames), 0
+	for i, f := range frames {
+		if f.start+int64(f.dSize) > r.Start && f.start < end {
+			first, last = min(first, i), i+1
+		}
+	}
+	if first >= last {
+		return &framesReader{}
+	}
+	return &framesReader{
+		frames:     frames[first:last],
+		start:      r.Start,
+		end:        end,
+		decompress: c.decompress,
+		open:       open,
+	}
+}
+
+func (r *framesReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if len(r.frames) == 0 {
+			return 0, io.EOF
+		}
+		if r.remote == nil {
+			last := r.frames[len(r.frames)-1]
+			rc, err := r.open(http_range.Range{Start: r.frames[0].offset, Length: last.offset + int64(last.cSize) - r.frames[0].offset})
+			if err != nil {
+				return 0, err
+			}
+			r.remote = rc
+		}
+		f := r.frames[0]
+		compressed := make([]byte, f.cSize)
+		if _, err := io.ReadFull(r.remote, compressed); err != nil {
+			return 0, err
+		}
+		block, err := r.decompress(compressed)
+		if err != nil {
+			return 0, err
+		}
+		if int64(len(block)) != int64(f.dSize) {
+			return 0, errors.Errorf("size of frame %d mismatches %d", len(block), f.dSize)
+		}
+		r.buf = block[max(r.start-f.start, 0):min(r.end-f.start, int64(f.dSize))]
+		r.frames = r.frames[1:]
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *framesReader) Close() error {
+	if r.remote == nil {
+		return nil
+	}
+	err := r.remote.Close()
+	r.remote = nil
+	return err
+}
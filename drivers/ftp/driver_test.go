@@ -0,0 +1,287 @@
+package ftp_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/drivers/ftp"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+)
+
+// server is a minimal ftp server on a local folder, it supports the commands used by the driver in passive mode
+type server struct {
+	root     string
+	user     string
+	password string
+}
+
+type session struct {
+	*server
+	conn     *textproto.Conn
+	cwd      string
+	data     net.Listener
+	offset   int64
+	renaming string
+}
+
+func serve(t *testing.T, s *server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			ss := &session{server: s, conn: textproto.NewConn(conn), cwd: "/"}
+			go ss.handle()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (s *session) reply(code int, format string, args ...any) {
+	_ = s.conn.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+// local returns the local path of the path relative to the current dir
+func (s *session) local(path string) (string, string) {
+	if !stdpath.IsAbs(path) {
+		path = stdpath.Join(s.cwd, path)
+	}
+	path = stdpath.Clean(path)
+	return path, filepath.Join(s.root, filepath.FromSlash(path))
+}
+
+func (s *session) handle() {
+	defer s.conn.Close()
+	s.reply(220, "ready")
+	for {
+		line, err := s.conn.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		if !s.exec(strings.ToUpper(cmd), arg) {
+			return
+		}
+	}
+}
+
+func (s *session) exec(cmd, arg string) bool {
+	switch cmd {
+	case "USER":
+		if arg != s.user {
+			s.reply(530, "invalid user")
+			break
+		}
+		s.reply(331, "password required")
+	case "PASS":
+		if arg != s.password {
+			s.reply(530, "invalid password")
+			break
+		}
+		s.reply(230, "logged in")
+	case "FEAT":
+		_ = s.conn.PrintfLine("211-Features:\r\n UTF8\r\n EPSV\r\n REST STREAM\r\n211 End")
+	case "TYPE", "NOOP":
+		s.reply(200, "ok")
+	case "OPTS":
+		s.reply(200, "utf8 on")
+	case "PWD":
+		s.reply(257, "%q is the current directory", s.cwd)
+	case "CWD", "CDUP":
+		if cmd == "CDUP" {
+			arg = ".."
+		}
+		path, local := s.local(arg)
+		if fi, err := os.Stat(local); err != nil || !fi.IsDir() {
+			s.reply(550, "not a directory")
+			break
+		}
+		s.cwd = path
+		s.reply(250, "ok")
+	case "EPSV":
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			s.reply(425, "%v", err)
+			break
+		}
+		s.data = l
+		s.reply(229, "Entering Extended Passive Mode (|||%d|)", l.Addr().(*net.TCPAddr).Port)
+	case "REST":
+		offset, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			s.reply(501, "invalid offset")
+			break
+		}
+		s.offset = offset
+		s.reply(350, "restarting at %d", offset)
+	case "LIST":
+		_, local := s.local(strings.TrimPrefix(arg, "-a "))
+		entries, err := os.ReadDir(local)
+		if err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.transfer(func(w io.Writer) error {
+			for _, e := range entries {
+				fi, err := e.Info()
+				if err != nil {
+					return err
+				}
+				mode := "-rw-r--r--"
+				if fi.IsDir() {
+					mode = "drwxr-xr-x"
+				}
+				_, err = fmt.Fprintf(w, "%s 1 alist alist %d %s %s\r\n", mode, fi.Size(), fi.ModTime().Format("Jan _2 15:04"), fi.Name())
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "RETR":
+		_, local := s.local(arg)
+		f, err := os.Open(local)
+		if err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		_, err = f.Seek(s.offset, io.SeekStart)
+		s.offset = 0
+		if err != nil {
+			_ = f.Close()
+			s.reply(550, "%v", err)
+			break
+		}
+		s.transfer(func(w io.Writer) error {
+			defer f.Close()
+			_, err := io.Copy(w, f)
+			return err
+		})
+	case "STOR":
+		_, local := s.local(arg)
+		f, err := os.Create(local)
+		if err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.receive(f)
+	case "MKD":
+		path, local := s.local(arg)
+		if err := os.Mkdir(local, 0o755); err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.reply(257, "%q created", path)
+	case "RMD", "DELE":
+		_, local := s.local(arg)
+		if err := os.Remove(local); err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.reply(250, "removed")
+	case "RNFR":
+		_, local := s.local(arg)
+		if _, err := os.Stat(local); err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.renaming = local
+		s.reply(350, "ready for destination")
+	case "RNTO":
+		_, local := s.local(arg)
+		err := os.Rename(s.renaming, local)
+		s.renaming = ""
+		if err != nil {
+			s.reply(550, "%v", err)
+			break
+		}
+		s.reply(250, "renamed")
+	case "QUIT":
+		s.reply(221, "bye")
+		return false
+	default:
+		s.reply(502, "not implemented")
+	}
+	return true
+}
+
+func (s *session) accept() (net.Conn, bool) {
+	if s.data == nil {
+		s.reply(425, "use EPSV first")
+		return nil, false
+	}
+	defer func() {
+		_ = s.data.Close()
+		s.data = nil
+	}()
+	conn, err := s.data.Accept()
+	if err != nil {
+		s.reply(425, "%v", err)
+		return nil, false
+	}
+	s.reply(150, "opening data connection")
+	return conn, true
+}
+
+// transfer sends the data written by write, the client may close the connection before the end
+func (s *session) transfer(write func(w io.Writer) error) {
+	conn, ok := s.accept()
+	if !ok {
+		return
+	}
+	w := bufio.NewWriter(conn)
+	err := write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	_ = conn.Close()
+	if err != nil {
+		s.reply(426, "transfer aborted")
+		return
+	}
+	s.reply(226, "transfer complete")
+}
+
+func (s *session) receive(f *os.File) {
+	defer f.Close()
+	conn, ok := s.accept()
+	if !ok {
+		return
+	}
+	_, err := io.Copy(f, conn)
+	_ = conn.Close()
+	if err != nil {
+		s.reply(426, "transfer aborted")
+		return
+	}
+	s.reply(226, "transfer complete")
+}
+
+func TestConformance(t *testing.T) {
+	d := &ftp.FTP{Addition: ftp.Addition{
+		Address:  serve(t, &server{root: t.TempDir(), user: "alist", password: "secret"}),
+		Username: "alist",
+		Password: "secret",
+		RootPath: driver.RootPath{RootFolderPath: "/"},
+	}}
+	drivertest.Init(t, d)
+	drivertest.Run(t, d, drivertest.Options{})
+}
@@ -63,7 +63,7 @@ func (r *FileReader) ReadAt(buf []byte, off int64) (n int, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if off != r.readAtOffset {
+	if r.resp != nil && off != r.readAtOffset {
 		//have to restart the connection, to correct offset
 		_ = r.resp.Close()
 		r.resp = nil
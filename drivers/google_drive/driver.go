@@ -3,8 +3,12 @@ package google_drive
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -20,6 +24,8 @@ type GoogleDrive struct {
 	AccessToken            string
 	ServiceAccountFile     int
 	ServiceAccountFileList []string
+	permissionId           string
+	mutex                  sync.Mutex
 }
 
 func (d *GoogleDrive) Config() driver.Config {
@@ -166,4 +172,104 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+// getPermissionId returns the id of the user, which is fetched on the first call
+func (d *GoogleDrive) getPermissionId() (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.permissionId != "" {
+		return d.permissionId, nil
+	}
+	var resp About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "user(permissionId)")
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	d.permissionId = resp.User.PermissionId
+	return d.permissionId, nil
+}
+
+// SameAccount reports whether dst is a storage of the same user,
+// the storages of service accounts are never the same as the accounts are rotated
+func (d *GoogleDrive) SameAccount(dst driver.Driver) bool {
+	s, ok := dst.(*GoogleDrive)
+	if !ok || d.isServiceAccount() || s.isServiceAccount() {
+		return false
+	}
+	srcId, err := d.getPermissionId()
+	if err != nil {
+		return false
+	}
+	dstId, err := s.getPermissionId()
+	return err == nil && srcId != "" && srcId == dstId
+}
+
+func (d *GoogleDrive) isServiceAccount() bool {
+	_, err := os.Stat(d.RefreshToken)
+	return err == nil
+}
+
+// CopyTo copies the file, the folders can't be copied by the api
+func (d *GoogleDrive) CopyTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	if srcObj.IsDir() {
+		return errs.NotSupport
+	}
+	data := base.Json{
+		"name":    srcObj.GetName(),
+		"parents": []string{dstDir.GetID()},
+	}
+	url := "https://www.googleapis.com/drive/v3/files/" + srcObj.GetID() + "/copy"
+	_, err := d.request(url, http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data)
+	}, nil)
+	return err
+}
+
+func (d *GoogleDrive) MoveTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	var file File
+	url := "https://www.googleapis.com/drive/v3/files/" + srcObj.GetID()
+	_, err := d.request(url, http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "parents")
+	}, &file)
+	if err != nil {
+		return err
+	}
+	_, err = d.request(url, http.MethodPatch, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			"addParents":    dstDir.GetID(),
+			"removeParents": strings.Join(file.Parents, ","),
+		})
+	}, nil)
+	return err
+}
+
+// GetSpace returns the storage quota of the account, the total is 0 if the quota is unlimited
+func (d *GoogleDrive) GetSpace(ctx context.Context) (*model.Space, error) {
+	var resp About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", "storageQuota")
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	total, _ := strconv.ParseInt(resp.StorageQuota.Limit, 10, 64)
+	used, _ := strconv.ParseInt(resp.StorageQuota.Usage, 10, 64)
+	free := int64(math.MaxInt64)
+	if total > 0 {
+		free = total - used
+	}
+	return &model.Space{Total: total, Used: used, Free: free}, nil
+}
+
+// HealthCheck checks the token by getting the space, cheaper than listing the root
+func (d *GoogleDrive) HealthCheck(ctx context.Context) error {
+	_, err := d.GetSpace(ctx)
+	return err
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
+var _ driver.CrossStorageCopier = (*GoogleDrive)(nil)
+var _ driver.CrossStorageMover = (*GoogleDrive)(nil)
+var _ driver.SpaceInfo = (*GoogleDrive)(nil)
+var _ driver.HealthChecker = (*GoogleDrive)(nil)
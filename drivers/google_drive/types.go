@@ -32,9 +32,20 @@ type File struct {
 		TargetMimeType string `json:"targetMimeType"`
 	} `json:"shortcutDetails"`
 
-	MD5Checksum    string `json:"md5Checksum"`
-	SHA1Checksum   string `json:"sha1Checksum"`
-	SHA256Checksum string `json:"sha256Checksum"`
+	MD5Checksum    string   `json:"md5Checksum"`
+	SHA1Checksum   string   `json:"sha1Checksum"`
+	SHA256Checksum string   `json:"sha256Checksum"`
+	Parents        []string `json:"parents"`
+}
+
+type About struct {
+	User struct {
+		PermissionId string `json:"permissionId"`
+	} `json:"user"`
+	StorageQuota struct {
+		Limit string `json:"limit"` // absent if unlimited
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
 }
 
 func fileToObj(f File) *model.ObjThumb {
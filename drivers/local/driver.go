@@ -23,6 +23,7 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/times"
 	cp "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -328,4 +329,17 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) GetSpace(ctx context.Context) (*model.Space, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.Space{
+		Total: int64(usage.Total),
+		Used:  int64(usage.Used),
+		Free:  int64(usage.Free),
+	}, nil
+}
+
 var _ driver.Driver = (*Local)(nil)
+var _ driver.SpaceInfo = (*Local)(nil)
@@ -0,0 +1,21 @@
+package local_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+)
+
+func TestConformance(t *testing.T) {
+	d := &local.Local{Addition: local.Addition{
+		RootPath:         driver.RootPath{RootFolderPath: t.TempDir()},
+		ThumbConcurrency: "0",
+		ShowHidden:       true,
+		MkdirPerm:        "777",
+		RecycleBinPath:   "delete permanently",
+	}}
+	drivertest.Init(t, d)
+	drivertest.Run(t, d, drivertest.Options{})
+}
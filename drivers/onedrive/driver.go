@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -21,6 +22,7 @@ type Onedrive struct {
 	Addition
 	AccessToken string
 	root        *Object
+	driveId     string
 	mutex       sync.Mutex
 }
 
@@ -207,4 +209,67 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	return err
 }
 
+// getDriveId returns the id of the drive, which is fetched on the first call
+func (d *Onedrive) getDriveId() (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.driveId != "" {
+		return d.driveId, nil
+	}
+	root, err := d.GetFile("/")
+	if err != nil {
+		return "", err
+	}
+	d.driveId = root.ParentReference.DriveId
+	return d.driveId, nil
+}
+
+// SameAccount reports whether dst is a storage of the same drive, then the paths and ids are valid in both storages
+func (d *Onedrive) SameAccount(dst driver.Driver) bool {
+	s, ok := dst.(*Onedrive)
+	if !ok || s.Region != d.Region {
+		return false
+	}
+	srcDriveId, err := d.getDriveId()
+	if err != nil {
+		return false
+	}
+	dstDriveId, err := s.getDriveId()
+	return err == nil && srcDriveId != "" && srcDriveId == dstDriveId
+}
+
+func (d *Onedrive) CopyTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Copy(ctx, srcObj, dstDir)
+}
+
+func (d *Onedrive) MoveTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.Move(ctx, srcObj, dstDir)
+}
+
+// GetSpace returns the quota of the drive
+func (d *Onedrive) GetSpace(ctx context.Context) (*model.Space, error) {
+	var resp Drive
+	_, err := d.Request(strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root"), http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx)
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Space{
+		Total: resp.Quota.Total,
+		Used:  resp.Quota.Used,
+		Free:  resp.Quota.Remaining,
+	}, nil
+}
+
+// HealthCheck checks the token by getting the space, cheaper than listing the root
+func (d *Onedrive) HealthCheck(ctx context.Context) error {
+	_, err := d.GetSpace(ctx)
+	return err
+}
+
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.CrossStorageCopier = (*Onedrive)(nil)
+var _ driver.CrossStorageMover = (*Onedrive)(nil)
+var _ driver.SpaceInfo = (*Onedrive)(nil)
+var _ driver.HealthChecker = (*Onedrive)(nil)
@@ -89,3 +89,11 @@ type FileSystemInfoFacet struct {
 	CreatedDateTime      time.Time `json:"createdDateTime,omitempty"`      // The UTC date and time the file was created on a client.
 	LastModifiedDateTime time.Time `json:"lastModifiedDateTime,omitempty"` // The UTC date and time the file was last modified on a client.
 }
+
+type Drive struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Used      int64 `json:"used"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -135,33 +136,40 @@ func (d *QuarkOrUC) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *QuarkOrUC) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
-	tempFile, err := stream.CacheFullInTempFile()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tempFile.Close()
-	}()
-	m := md5.New()
-	_, err = utils.CopyWithBuffer(m, tempFile)
-	if err != nil {
-		return err
-	}
-	_, err = tempFile.Seek(0, io.SeekStart)
-	if err != nil {
-		return err
-	}
-	md5Str := hex.EncodeToString(m.Sum(nil))
-	s := sha1.New()
-	_, err = utils.CopyWithBuffer(s, tempFile)
-	if err != nil {
-		return err
-	}
-	_, err = tempFile.Seek(0, io.SeekStart)
-	if err != nil {
-		return err
+	// the hashes carried by the stream are used if complete, then the file isn't read if uploaded rapidly
+	md5Str := strings.ToLower(stream.GetHash().GetHash(utils.MD5))
+	sha1Str := strings.ToLower(stream.GetHash().GetHash(utils.SHA1))
+	var tempFile model.File
+	var err error
+	if len(md5Str) != utils.MD5.Width || len(sha1Str) != utils.SHA1.Width {
+		tempFile, err = stream.CacheFullInTempFile()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tempFile.Close()
+		}()
+		m := md5.New()
+		_, err = utils.CopyWithBuffer(m, tempFile)
+		if err != nil {
+			return err
+		}
+		_, err = tempFile.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		md5Str = hex.EncodeToString(m.Sum(nil))
+		s := sha1.New()
+		_, err = utils.CopyWithBuffer(s, tempFile)
+		if err != nil {
+			return err
+		}
+		_, err = tempFile.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		sha1Str = hex.EncodeToString(s.Sum(nil))
 	}
-	sha1Str := hex.EncodeToString(s.Sum(nil))
 	// pre
 	pre, err := d.upPre(stream, dstDir.GetID())
 	if err != nil {
@@ -176,6 +184,15 @@ func (d *QuarkOrUC) Put(ctx context.Context, dstDir model.Obj, stream model.File
 	if finish {
 		return nil
 	}
+	if tempFile == nil {
+		tempFile, err = stream.CacheFullInTempFile()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tempFile.Close()
+		}()
+	}
 	// part up
 	partSize := pre.Metadata.PartSize
 	var bytes []byte
@@ -218,4 +235,9 @@ func (d *QuarkOrUC) Put(ctx context.Context, dstDir model.Obj, stream model.File
 	return d.upFinish(pre)
 }
 
+func (d *QuarkOrUC) RapidUploadHash() []*utils.HashType {
+	return []*utils.HashType{utils.MD5, utils.SHA1}
+}
+
 var _ driver.Driver = (*QuarkOrUC)(nil)
+var _ driver.RapidUpload = (*QuarkOrUC)(nil)
@@ -141,7 +141,7 @@ func (d *S3) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *S3) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
-	err := d.copy(ctx, srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName), srcObj.IsDir())
+	err := d.copy(ctx, srcObj, d.Bucket, stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
 	if err != nil {
 		return err
 	}
@@ -149,7 +149,7 @@ func (d *S3) Rename(ctx context.Context, srcObj model.Obj, newName string) error
 }
 
 func (d *S3) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
-	return d.copy(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()), srcObj.IsDir())
+	return d.copy(ctx, srcObj, d.Bucket, stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
 }
 
 func (d *S3) Remove(ctx context.Context, obj model.Obj) error {
@@ -177,4 +177,32 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreame
 	return err
 }
 
+// SameAccount reports whether the storage can copy objects to dst directly, i.e. dst is of the same service and credentials
+func (d *S3) SameAccount(dst driver.Driver) bool {
+	s, ok := dst.(*S3)
+	return ok && s.config.Name == d.config.Name && s.Endpoint == d.Endpoint && s.Region == d.Region &&
+		s.AccessKeyID == d.AccessKeyID && s.SecretAccessKey == d.SecretAccessKey && s.SessionToken == d.SessionToken
+}
+
+func (d *S3) CopyTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	return d.copy(ctx, srcObj, dst.(*S3).Bucket, stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *S3) MoveTo(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	err := d.CopyTo(ctx, srcObj, dst, dstDir)
+	if err != nil {
+		return err
+	}
+	return d.Remove(ctx, srcObj)
+}
+
+// HealthCheck checks the credentials and the bucket without listing
+func (d *S3) HealthCheck(ctx context.Context) error {
+	_, err := d.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: &d.Bucket})
+	return err
+}
+
 var _ driver.Driver = (*S3)(nil)
+var _ driver.CrossStorageCopier = (*S3)(nil)
+var _ driver.CrossStorageMover = (*S3)(nil)
+var _ driver.HealthChecker = (*S3)(nil)
@@ -0,0 +1,140 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/drivers/s3"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	server "github.com/alist-org/alist/v3/server/s3"
+)
+
+func init() {
+	drivertest.InitDB()
+}
+
+// serve serves the s3 of alist with the buckets of the local storages mounted at /local_<bucket>
+func serve(t *testing.T, accessKey, secretKey string, buckets ...string) string {
+	var items []string
+	for _, bucket := range buckets {
+		mountPath := "/local_" + bucket
+		drivertest.NewLocalStorage(t, mountPath)
+		items = append(items, fmt.Sprintf(`{"name":%q,"path":%q}`, bucket, mountPath))
+	}
+	err := op.SaveSettingItems([]model.SettingItem{
+		{Key: conf.S3AccessKeyId, Value: accessKey, Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3SecretAccessKey, Value: secretKey, Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3Buckets, Value: "[" + strings.Join(items, ",") + "]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+	})
+	if err != nil {
+		t.Fatalf("failed save settings: %+v", err)
+	}
+	h, err := server.NewServer(context.Background())
+	if err != nil {
+		t.Fatalf("failed create s3 server: %+v", err)
+	}
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func addition(endpoint, bucket string) s3.Addition {
+	return s3.Addition{
+		Bucket:            bucket,
+		Endpoint:          endpoint,
+		Region:            "alist",
+		AccessKeyID:       "access",
+		SecretAccessKey:   "secret",
+		SignURLExpire:     4,
+		ForcePathStyle:    true,
+		ListObjectVersion: "v2",
+		RootPath:          driver.RootPath{RootFolderPath: "/"},
+	}
+}
+
+func TestConformance(t *testing.T) {
+	// the config is set by the registered constructor
+	constructor, err := op.GetDriver("S3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := constructor().(*s3.S3)
+	d.Addition = addition(serve(t, "access", "secret", "alist"), "alist")
+	drivertest.Init(t, d)
+	drivertest.Run(t, d, drivertest.Options{})
+}
+
+func TestCrossStorage(t *testing.T) {
+	ctx := context.Background()
+	endpoint := serve(t, "access", "secret", "src", "dst")
+	storages := map[string]driver.Driver{}
+	for _, bucket := range []string{"src", "dst"} {
+		data, _ := json.Marshal(addition(endpoint, bucket))
+		mountPath := "/s3_" + bucket
+		id, err := op.CreateStorage(ctx, model.Storage{Driver: "S3", MountPath: mountPath, Addition: string(data)})
+		if err != nil {
+			t.Fatalf("failed create storage: %+v", err)
+		}
+		t.Cleanup(func() {
+			_ = op.DeleteStorageById(ctx, id)
+		})
+		if storages[bucket], err = op.GetStorageByMountPath(mountPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src, dst := storages["src"], storages["dst"]
+	if err := op.MakeDir(ctx, src, "/dir"); err != nil {
+		t.Fatal(err)
+	}
+	content := drivertest.Content(1024, 1)
+	if err := drivertest.Put(ctx, src, "/dir", "a.txt", content); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := op.CrossStorageCopy(ctx, src, "/dir/a.txt", dst, "/"); err != nil {
+		t.Fatalf("failed copy: %+v", err)
+	}
+	if _, err := op.Get(ctx, dst, "/a.txt"); err != nil {
+		t.Fatalf("copied object not found: %+v", err)
+	}
+	if _, err := op.Get(ctx, src, "/dir/a.txt"); err != nil {
+		t.Fatalf("src object removed by copy: %+v", err)
+	}
+
+	if err := op.CrossStorageMove(ctx, src, "/dir", dst, "/"); err != nil {
+		t.Fatalf("failed move: %+v", err)
+	}
+	if _, err := op.Get(ctx, src, "/dir"); err == nil {
+		t.Fatal("src dir exists after move")
+	}
+	data, err := drivertest.Read(ctx, dst, "/dir/a.txt", http_range.Range{Length: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Fatal("moved content mismatch")
+	}
+
+	// not the same account
+	constructor, _ := op.GetDriver("S3")
+	other := constructor().(*s3.S3)
+	other.Addition = addition(endpoint, "src")
+	other.Region = "other"
+	err = op.CrossStorageCopy(ctx, other, "/", dst, "/")
+	if !errors.Is(err, errs.NotSupport) {
+		t.Fatalf("expected not support, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"path"
 	"strings"
@@ -15,11 +16,18 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	log "github.com/sirupsen/logrus"
 )
 
 // do others that not defined in Driver interface
 
+const (
+	// the max size of an object copied by CopyObject
+	maxCopySize  = 5 * utils.GB
+	copyPartSize = utils.GB
+)
+
 func (d *S3) initSession() error {
 	var err error
 	accessKeyID, secretAccessKey, sessionToken := d.AccessKeyID, d.SecretAccessKey, d.SessionToken
@@ -186,26 +194,75 @@ func (d *S3) listV2(prefix string, args model.ListArgs) ([]model.Obj, error) {
 	return files, nil
 }
 
-func (d *S3) copy(ctx context.Context, src string, dst string, isDir bool) error {
-	if isDir {
-		return d.copyDir(ctx, src, dst)
+func (d *S3) copy(ctx context.Context, srcObj model.Obj, dstBucket string, dst string) error {
+	if srcObj.IsDir() {
+		return d.copyDir(ctx, srcObj.GetPath(), dstBucket, dst)
 	}
-	return d.copyFile(ctx, src, dst)
+	return d.copyFile(ctx, srcObj.GetPath(), dstBucket, dst, srcObj.GetSize())
 }
 
-func (d *S3) copyFile(ctx context.Context, src string, dst string) error {
+// copyFile copies the object to the bucket, the object larger than the limit of CopyObject is copied by parts
+func (d *S3) copyFile(ctx context.Context, src string, dstBucket string, dst string, size int64) error {
 	srcKey := getKey(src, false)
 	dstKey := getKey(dst, false)
+	if size > maxCopySize {
+		return d.copyByParts(ctx, srcKey, dstBucket, dstKey, size)
+	}
 	input := &s3.CopyObjectInput{
-		Bucket:     &d.Bucket,
+		Bucket:     &dstBucket,
 		CopySource: aws.String("/" + d.Bucket + "/" + srcKey),
 		Key:        &dstKey,
 	}
-	_, err := d.client.CopyObject(input)
+	_, err := d.client.CopyObjectWithContext(ctx, input)
+	return err
+}
+
+func (d *S3) copyByParts(ctx context.Context, srcKey string, dstBucket string, dstKey string, size int64) error {
+	upload, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: &dstBucket,
+		Key:    &dstKey,
+	})
+	if err != nil {
+		return err
+	}
+	abort := func() {
+		_, _ = d.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   &dstBucket,
+			Key:      &dstKey,
+			UploadId: upload.UploadId,
+		})
+	}
+	partSize := max(size/(s3manager.MaxUploadParts-1), copyPartSize)
+	var parts []*s3.CompletedPart
+	for i, start := int64(1), int64(0); start < size; i, start = i+1, start+partSize {
+		end := min(start+partSize, size) - 1
+		res, err := d.client.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:          &dstBucket,
+			Key:             &dstKey,
+			UploadId:        upload.UploadId,
+			PartNumber:      aws.Int64(i),
+			CopySource:      aws.String("/" + d.Bucket + "/" + srcKey),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+		if err != nil {
+			abort()
+			return err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: res.CopyPartResult.ETag, PartNumber: aws.Int64(i)})
+	}
+	_, err = d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &dstBucket,
+		Key:             &dstKey,
+		UploadId:        upload.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		abort()
+	}
 	return err
 }
 
-func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
+func (d *S3) copyDir(ctx context.Context, src string, dstBucket string, dst string) error {
 	objs, err := op.List(ctx, d, src, model.ListArgs{S3ShowPlaceholder: true})
 	if err != nil {
 		return err
@@ -214,9 +271,9 @@ func (d *S3) copyDir(ctx context.Context, src string, dst string) error {
 		cSrc := path.Join(src, obj.GetName())
 		cDst := path.Join(dst, obj.GetName())
 		if obj.IsDir() {
-			err = d.copyDir(ctx, cSrc, cDst)
+			err = d.copyDir(ctx, cSrc, dstBucket, cDst)
 		} else {
-			err = d.copyFile(ctx, cSrc, cDst)
+			err = d.copyFile(ctx, cSrc, dstBucket, cDst, obj.GetSize())
 		}
 		if err != nil {
 			return err
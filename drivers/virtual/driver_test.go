@@ -0,0 +1,19 @@
+package virtual_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/drivers/virtual"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+)
+
+func TestConformance(t *testing.T) {
+	d := &virtual.Virtual{Addition: virtual.Addition{
+		NumFile:     3,
+		NumFolder:   3,
+		MaxFileSize: 4096,
+		MinFileSize: 1024,
+	}}
+	drivertest.Init(t, d)
+	drivertest.Run(t, d, drivertest.Options{Stateless: true})
+}
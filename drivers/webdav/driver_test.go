@@ -0,0 +1,48 @@
+package webdav_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/drivers/webdav"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/driver/drivertest"
+	"github.com/alist-org/alist/v3/internal/model"
+	server "github.com/alist-org/alist/v3/server/webdav"
+)
+
+func init() {
+	drivertest.InitDB()
+}
+
+// serve serves the webdav of alist with a local storage mounted at /local
+func serve(t *testing.T, username, password string) string {
+	drivertest.NewLocalStorage(t, "/local")
+	user := &model.User{Username: username, Role: model.ADMIN, BasePath: "/", Permission: 0xffff}
+	handler := &server.Handler{Prefix: "/dav", LockSystem: server.NewMemLS()}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="alist"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL + "/dav"
+}
+
+func TestConformance(t *testing.T) {
+	d := &webdav.WebDav{Addition: webdav.Addition{
+		Vendor:   "other",
+		Address:  serve(t, "alist", "secret"),
+		Username: "alist",
+		Password: "secret",
+		RootPath: driver.RootPath{RootFolderPath: "/local"},
+	}}
+	drivertest.Init(t, d)
+	drivertest.Run(t, d, drivertest.Options{})
+}
@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version of the archive format, bump it when the format changes incompatibly
+const Version = 1
+
+type Archive struct {
+	Version   int                 `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	Storages  []model.Storage     `json:"storages"`
+	Users     []User              `json:"users"`
+	Metas     []model.Meta        `json:"metas"`
+	Settings  []model.SettingItem `json:"settings"`
+	Tasks     []model.TaskItem    `json:"tasks"`
+}
+
+// User carries the credentials which are hidden from the json of model.User
+type User struct {
+	model.User
+	PwdHash   string `json:"pwd_hash"`
+	PwdTS     int64  `json:"pwd_ts"`
+	Salt      string `json:"salt"`
+	OtpSecret string `json:"otp_secret"`
+	Authn     string `json:"authn"`
+}
+
+func fromUser(u model.User) User {
+	return User{User: u, PwdHash: u.PwdHash, PwdTS: u.PwdTS, Salt: u.Salt, OtpSecret: u.OtpSecret, Authn: u.Authn}
+}
+
+func (u User) toUser() model.User {
+	res := u.User
+	res.PwdHash, res.PwdTS, res.Salt, res.OtpSecret, res.Authn = u.PwdHash, u.PwdTS, u.Salt, u.OtpSecret, u.Authn
+	return res
+}
+
+// file is the envelope written to disk, the archive is encrypted if a password is given
+type file struct {
+	Version   int             `json:"version"`
+	Encrypted bool            `json:"encrypted"`
+	Salt      []byte          `json:"salt,omitempty"`
+	Nonce     []byte          `json:"nonce,omitempty"`
+	Cipher    []byte          `json:"cipher,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+func deriveKey(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ErrPasswordRequired is returned by Marshal without a password, as the archive contains
+// the credentials of the storages and the secrets of the users
+var ErrPasswordRequired = errors.New("password is required to export the backup, or export it in plaintext explicitly")
+
+// Marshal encodes the archive encrypted with AES-GCM by the password
+func (a *Archive) Marshal(password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	f := file{Version: a.Version, Encrypted: true}
+	f.Salt = make([]byte, 16)
+	if _, err := rand.Read(f.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(password, f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return nil, err
+	}
+	f.Cipher = aead.Seal(nil, f.Nonce, data, nil)
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// MarshalPlaintext encodes the archive without encryption, the secrets in it are readable by anyone with the file
+func (a *Archive) MarshalPlaintext() ([]byte, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(file{Version: a.Version, Data: data}, "", "  ")
+}
+
+// Unmarshal decodes an archive written by Marshal or MarshalPlaintext
+func Unmarshal(data []byte, password string) (*Archive, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrap(err, "not a backup archive")
+	}
+	if f.Version < 1 || f.Version > Version {
+		return nil, errors.Errorf("unsupported backup version: %d", f.Version)
+	}
+	if f.Encrypted {
+		if password == "" {
+			return nil, errors.New("the backup is encrypted, password is required")
+		}
+		aead, err := deriveKey(password, f.Salt)
+		if err != nil {
+			return nil, err
+		}
+		if len(f.Nonce) != aead.NonceSize() {
+			return nil, errors.New("invalid nonce")
+		}
+		f.Data, err = aead.Open(nil, f.Nonce, f.Cipher, nil)
+		if err != nil {
+			return nil, errors.New("wrong password or the backup is corrupted")
+		}
+	}
+	var a Archive
+	if err := json.Unmarshal(f.Data, &a); err != nil {
+		return nil, errors.Wrap(err, "failed decode backup")
+	}
+	return &a, nil
+}
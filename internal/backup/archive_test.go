@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestMarshal(t *testing.T) {
+	a := &Archive{
+		Version: Version,
+		Users: []User{fromUser(model.User{
+			Username: "admin",
+			PwdHash:  "hash",
+			Salt:     "salt",
+			Role:     model.ADMIN,
+		})},
+		Settings: []model.SettingItem{{Key: "site_title", Value: "AList"}},
+	}
+	for _, password := range []string{"", "secret"} {
+		var data []byte
+		var err error
+		if password == "" {
+			data, err = a.MarshalPlaintext()
+		} else {
+			data, err = a.Marshal(password)
+		}
+		if err != nil {
+			t.Fatalf("marshal with password %q: %+v", password, err)
+		}
+		res, err := Unmarshal(data, password)
+		if err != nil {
+			t.Fatalf("unmarshal with password %q: %+v", password, err)
+		}
+		u := res.Users[0].toUser()
+		if u.Username != "admin" || u.PwdHash != "hash" || u.Salt != "salt" || !u.IsAdmin() {
+			t.Errorf("user is not restored: %+v", u)
+		}
+		if len(res.Settings) != 1 || res.Settings[0].Value != "AList" {
+			t.Errorf("settings are not restored: %+v", res.Settings)
+		}
+	}
+	if _, err := a.Marshal(""); err != ErrPasswordRequired {
+		t.Errorf("expect password required, got %v", err)
+	}
+	data, _ := a.Marshal("secret")
+	if _, err := Unmarshal(data, "wrong"); err == nil {
+		t.Error("expect error with wrong password")
+	}
+	if _, err := Unmarshal(data, ""); err == nil {
+		t.Error("expect error without password")
+	}
+}
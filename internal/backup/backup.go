@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Mode string
+
+const (
+	// Merge keeps the existing config, storages, users and metas are matched by mount path, username and path
+	Merge Mode = "merge"
+	// Replace drops the existing storages, users, metas and task data
+	Replace Mode = "replace"
+)
+
+// Export collects the config from the database
+func Export() (*Archive, error) {
+	storages, err := db.GetAllStorages()
+	if err != nil {
+		return nil, err
+	}
+	users, err := db.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	metas, err := db.GetAllMetas()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := db.GetSettingItems()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := db.GetTaskItems()
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		Version:   Version,
+		CreatedAt: time.Now(),
+		Storages:  storages,
+		Users:     utils.MustSliceConvert(users, fromUser),
+		Metas:     metas,
+		Settings:  settings,
+		Tasks:     tasks,
+	}, nil
+}
+
+// Import writes the archive to the database, the affected storages are reinitialized if reload is true,
+// which should be false if the storages are not loaded, e.g. in the cli. task data takes effect after restart
+func Import(ctx context.Context, a *Archive, mode Mode, reload bool) error {
+	switch mode {
+	case Replace:
+		return replace(ctx, a, reload)
+	case Merge, "":
+		return merge(ctx, a, reload)
+	default:
+		return errors.Errorf("unknown import mode: %s", mode)
+	}
+}
+
+func replace(ctx context.Context, a *Archive, reload bool) error {
+	hasAdmin := false
+	users := utils.MustSliceConvert(a.Users, func(u User) model.User {
+		hasAdmin = hasAdmin || u.IsAdmin()
+		return u.toUser()
+	})
+	if !hasAdmin {
+		return errors.New("the backup has no admin user")
+	}
+	if err := db.ReplaceConfig(a.Storages, users, a.Metas, a.Tasks); err != nil {
+		return err
+	}
+	if err := op.SaveSettingItems(a.Settings); err != nil {
+		return errors.WithMessage(err, "failed save settings")
+	}
+	if !reload {
+		return nil
+	}
+	return op.ReloadConfig(ctx)
+}
+
+func merge(ctx context.Context, a *Archive, reload bool) error {
+	for _, s := range a.Storages {
+		if err := mergeStorage(ctx, s, reload); err != nil {
+			return errors.WithMessagef(err, "failed import storage [%s]", s.MountPath)
+		}
+	}
+	for _, u := range a.Users {
+		if err := mergeUser(u.toUser()); err != nil {
+			return errors.WithMessagef(err, "failed import user [%s]", u.Username)
+		}
+	}
+	for _, m := range a.Metas {
+		if err := mergeMeta(m); err != nil {
+			return errors.WithMessagef(err, "failed import meta [%s]", m.Path)
+		}
+	}
+	if err := op.SaveSettingItems(a.Settings); err != nil {
+		return errors.WithMessage(err, "failed save settings")
+	}
+	for i := range a.Tasks {
+		t := a.Tasks[i]
+		var err error
+		if _, e := db.GetTaskDataByType(t.Key); e == nil {
+			err = db.UpdateTaskData(&t)
+		} else {
+			err = db.CreateTaskData(&t)
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed import task data [%s]", t.Key)
+		}
+	}
+	return nil
+}
+
+func mergeStorage(ctx context.Context, s model.Storage, reload bool) error {
+	s.MountPath = utils.FixAndCleanPath(s.MountPath)
+	s.ID = 0
+	if old, err := db.GetStorageByMountPath(s.MountPath); err == nil {
+		s.ID = old.ID
+		if reload && !old.Disabled {
+			if err := op.DisableStorage(ctx, old.ID); err != nil {
+				return err
+			}
+		}
+		if err := db.UpdateStorage(&s); err != nil {
+			return err
+		}
+	} else if err := db.CreateStorage(&s); err != nil {
+		return err
+	}
+	if !reload || s.Disabled {
+		return nil
+	}
+	if err := op.LoadStorage(ctx, s); err != nil {
+		// the storage is saved with the error status like when it is created
+		utils.Log.Warnf("failed load imported storage [%s]: %+v", s.MountPath, err)
+	}
+	return nil
+}
+
+// mergeUser matches the admin and guest by role, as only one of each exists
+func mergeUser(u model.User) error {
+	var old *model.User
+	var err error
+	if u.IsAdmin() || u.IsGuest() {
+		old, err = db.GetUserByRole(u.Role)
+	} else {
+		old, err = db.GetUserByName(u.Username)
+		if err == nil && (old.IsAdmin() || old.IsGuest()) {
+			return errors.New("username is taken by the admin or guest")
+		}
+	}
+	if err != nil {
+		u.ID = 0
+		return op.CreateUser(&u)
+	}
+	u.ID = old.ID
+	return op.UpdateUser(&u)
+}
+
+func mergeMeta(m model.Meta) error {
+	m.ID = 0
+	if old, err := db.GetMetaByPath(m.Path); err == nil {
+		m.ID = old.ID
+		return op.UpdateMeta(&m)
+	}
+	return op.CreateMeta(&m)
+}
@@ -145,6 +145,23 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.ForwardDirectLinkParams, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL},
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.LoginMaxFailures, Value: "5", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `failures of an ip or an account before it is locked, 0 to disable`},
+		{Key: conf.LoginLockDuration, Value: "5", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `in minutes, failures older than it are forgotten`},
+		{Key: conf.LoginCaptchaAfter, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PUBLIC, Help: `failures before a captcha is required, 0 to disable`},
+		{Key: conf.LoginCaptchaProvider, Value: "none", Type: conf.TypeSelect, Options: "none,recaptcha,hcaptcha,turnstile", Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.LoginCaptchaSiteKey, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.LoginCaptchaSecret, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.MfaRequired, Value: "none", Type: conf.TypeSelect, Options: "none,admin,write,all", Group: model.GLOBAL, Flag: model.PUBLIC, Help: `users required to enroll 2FA or webauthn, write means the users having any write permission`},
+		{Key: conf.MfaGracePeriod, Value: "7", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `in days, users not enrolled are blocked after it`},
+		{Key: conf.IPAllowList, Value: "", Type: conf.TypeText, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `ips or cidrs allowed to access, one per line, empty means all`},
+		{Key: conf.IPDenyList, Value: "", Type: conf.TypeText, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `ips or cidrs denied to access, one per line`},
+		{Key: conf.LoginHistoryDays, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `days to keep the login history`},
+		{Key: conf.StorageCheckInterval, Value: "10", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `in minutes, probe the enabled storages periodically, 0 to disable`},
+		{Key: conf.StorageCheckMaxFailures, Value: "3", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `failed checks before a storage is reinitialized and alerted`},
+		{Key: conf.StorageCheckHistoryDays, Value: "7", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `days to keep the storage check history`},
+		{Key: conf.StorageAlertWebhook, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `url to post a json when a storage is down or recovered`},
+		{Key: conf.OfflineDownloadExtractMaxSize, Value: "10240", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `max total size in MB extracted from the archives of a download, 0 means unlimited`},
+		{Key: conf.OfflineDownloadExtractMaxEntries, Value: "10000", Type: conf.TypeNumber, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `max entries extracted from the archives of a download, 0 means unlimited`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -168,6 +185,7 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.SSODefaultDir, Value: "/", Type: conf.TypeString, Group: model.SSO, Flag: model.PRIVATE},
 		{Key: conf.SSODefaultPermission, Value: "0", Type: conf.TypeNumber, Group: model.SSO, Flag: model.PRIVATE},
 		{Key: conf.SSOCompatibilityMode, Value: "false", Type: conf.TypeBool, Group: model.SSO, Flag: model.PUBLIC},
+		{Key: conf.SSOOIDCProviders, Value: "[]", Type: conf.TypeText, Group: model.SSO, Flag: model.PRIVATE},
 
 		// ldap settings
 		{Key: conf.LdapLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.LDAP, Flag: model.PUBLIC},
@@ -179,6 +197,17 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.LdapDefaultDir, Value: "/", Type: conf.TypeString, Group: model.LDAP, Flag: model.PRIVATE},
 		{Key: conf.LdapDefaultPermission, Value: "0", Type: conf.TypeNumber, Group: model.LDAP, Flag: model.PRIVATE},
 		{Key: conf.LdapLoginTips, Value: "login with ldap", Type: conf.TypeString, Group: model.LDAP, Flag: model.PUBLIC},
+		{Key: conf.LdapGroupSearchBase, Value: "", Type: conf.TypeString, Group: model.LDAP, Flag: model.PRIVATE},
+		{Key: conf.LdapGroupSearchFilter, Value: "", Type: conf.TypeString, Group: model.LDAP, Flag: model.PRIVATE},
+		{Key: conf.LdapGroupMapping, Value: "[]", Type: conf.TypeText, Group: model.LDAP, Flag: model.PRIVATE},
+		{Key: conf.LdapSyncInterval, Value: "0", Type: conf.TypeNumber, Group: model.LDAP, Flag: model.PRIVATE},
+
+		// scim settings
+		{Key: conf.ScimEnabled, Value: "false", Type: conf.TypeBool, Group: model.SCIM, Flag: model.PRIVATE},
+		{Key: conf.ScimToken, Value: "", Type: conf.TypeString, Group: model.SCIM, Flag: model.PRIVATE, Help: `bearer token of the identity provider`},
+		{Key: conf.ScimDefaultDir, Value: "/", Type: conf.TypeString, Group: model.SCIM, Flag: model.PRIVATE},
+		{Key: conf.ScimDefaultPermission, Value: "0", Type: conf.TypeNumber, Group: model.SCIM, Flag: model.PRIVATE},
+		{Key: conf.ScimGroupMapping, Value: "[]", Type: conf.TypeText, Group: model.SCIM, Flag: model.PRIVATE},
 
 		//s3 settings
 		{Key: conf.S3AccessKeyId, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
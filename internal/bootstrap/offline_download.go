@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"github.com/alist-org/alist/v3/internal/feed"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
@@ -15,3 +16,7 @@ func InitOfflineDownloadTools() {
 		}
 	}
 }
+
+func InitFeeds() {
+	feed.Init()
+}
@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var sessionCron *cron.Cron
+
+// InitSessionCleanup removes the expired sessions hourly instead of on each login
+func InitSessionCleanup() {
+	op.DeleteExpiredSessions()
+	sessionCron = cron.NewCron(time.Hour)
+	sessionCron.Do(op.DeleteExpiredSessions)
+}
@@ -0,0 +1,43 @@
+// Package captcha verifies the challenge required after repeated login failures
+package captcha
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/setting"
+)
+
+// Verifier verifies the response of a captcha solved by the client
+type Verifier interface {
+	Verify(ctx context.Context, response, ip string) error
+}
+
+// New creates a verifier with the secret in the settings
+type New func(secret string) Verifier
+
+var verifiers = map[string]New{}
+
+// Register registers a captcha provider, the name is the value of the login_captcha_provider setting
+func Register(name string, n New) {
+	verifiers[name] = n
+}
+
+// Enabled reports whether a captcha provider is configured
+func Enabled() bool {
+	_, ok := verifiers[setting.GetStr(conf.LoginCaptchaProvider)]
+	return ok
+}
+
+// Verify verifies the response with the configured provider, it passes if none is configured
+func Verify(ctx context.Context, response, ip string) error {
+	n, ok := verifiers[setting.GetStr(conf.LoginCaptchaProvider)]
+	if !ok {
+		return nil
+	}
+	if response == "" {
+		return errs.CaptchaRequired
+	}
+	return n(setting.GetStr(conf.LoginCaptchaSecret)).Verify(ctx, response, ip)
+}
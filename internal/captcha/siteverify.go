@@ -0,0 +1,58 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// siteVerify verifies the response with the siteverify api shared by recaptcha, hcaptcha and turnstile
+type siteVerify struct {
+	api    string
+	secret string
+}
+
+func (s *siteVerify) Verify(ctx context.Context, response, ip string) error {
+	form := url.Values{"secret": {s.secret}, "response": {response}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.api, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed verify captcha")
+	}
+	defer res.Body.Close()
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := utils.Json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "failed verify captcha")
+	}
+	if !result.Success {
+		return errors.WithMessagef(errs.CaptchaInvalid, "%v", result.ErrorCodes)
+	}
+	return nil
+}
+
+func siteVerifier(api string) New {
+	return func(secret string) Verifier {
+		return &siteVerify{api: api, secret: secret}
+	}
+}
+
+func init() {
+	Register("recaptcha", siteVerifier("https://www.google.com/recaptcha/api/siteverify"))
+	Register("hcaptcha", siteVerifier("https://api.hcaptcha.com/siteverify"))
+	Register("turnstile", siteVerifier("https://challenges.cloudflare.com/turnstile/v0/siteverify"))
+}
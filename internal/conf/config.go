@@ -77,6 +77,7 @@ type Config struct {
 	Cdn                   string      `json:"cdn" env:"CDN"`
 	JwtSecret             string      `json:"jwt_secret" env:"JWT_SECRET"`
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
+	AccessTokenExpiresIn  int         `json:"access_token_expires_in" env:"ACCESS_TOKEN_EXPIRES_IN"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
 	Scheme                Scheme      `json:"scheme"`
@@ -90,6 +91,9 @@ type Config struct {
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
+	// TrustedProxies are the ips or cidrs of the reverse proxies whose forwarded headers are trusted,
+	// only the loopback by default, add the cidr of the proxy if it connects from another address, e.g. docker
+	TrustedProxies []string `json:"trusted_proxies" env:"TRUSTED_PROXIES"`
 }
 
 func DefaultConfig() *Config {
@@ -109,7 +113,9 @@ func DefaultConfig() *Config {
 		},
 		JwtSecret:      random.String(16),
 		TokenExpiresIn: 48,
-		TempDir:        tempDir,
+		// in minutes, the client renews it with the refresh token, 0 means it lives as long as the session
+		AccessTokenExpiresIn: 15,
+		TempDir:              tempDir,
 		Database: Database{
 			Type:        "sqlite3",
 			Port:        0,
@@ -159,5 +165,6 @@ func DefaultConfig() *Config {
 			Port:   5246,
 			SSL:    false,
 		},
+		TrustedProxies: []string{"127.0.0.0/8", "::1/128"},
 	}
 }
@@ -44,6 +44,25 @@ const (
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
 
+	// login
+	LoginMaxFailures     = "login_max_failures"
+	LoginLockDuration    = "login_lock_duration"
+	LoginCaptchaAfter    = "login_captcha_after"
+	LoginCaptchaProvider = "login_captcha_provider"
+	LoginCaptchaSiteKey  = "login_captcha_site_key"
+	LoginCaptchaSecret   = "login_captcha_secret"
+	LoginHistoryDays     = "login_history_days"
+	MfaRequired          = "mfa_required"
+	MfaGracePeriod       = "mfa_grace_period"
+	IPAllowList          = "ip_allow_list"
+	IPDenyList           = "ip_deny_list"
+
+	// storage check
+	StorageCheckInterval    = "storage_check_interval"
+	StorageCheckMaxFailures = "storage_check_max_failures"
+	StorageCheckHistoryDays = "storage_check_history_days"
+	StorageAlertWebhook     = "storage_alert_webhook"
+
 	// index
 	SearchIndex     = "search_index"
 	AutoUpdateIndex = "auto_update_index"
@@ -76,6 +95,7 @@ const (
 	SSODefaultDir        = "sso_default_dir"
 	SSODefaultPermission = "sso_default_permission"
 	SSOCompatibilityMode = "sso_compatibility_mode"
+	SSOOIDCProviders     = "sso_oidc_providers"
 
 	// ldap
 	LdapLoginEnabled      = "ldap_login_enabled"
@@ -87,6 +107,17 @@ const (
 	LdapDefaultPermission = "ldap_default_permission"
 	LdapDefaultDir        = "ldap_default_dir"
 	LdapLoginTips         = "ldap_login_tips"
+	LdapGroupSearchBase   = "ldap_group_search_base"
+	LdapGroupSearchFilter = "ldap_group_search_filter"
+	LdapGroupMapping      = "ldap_group_mapping"
+	LdapSyncInterval      = "ldap_sync_interval"
+
+	// scim
+	ScimEnabled           = "scim_enabled"
+	ScimToken             = "scim_token"
+	ScimDefaultDir        = "scim_default_dir"
+	ScimDefaultPermission = "scim_default_permission"
+	ScimGroupMapping      = "scim_group_mapping"
 
 	// s3
 	S3Buckets         = "s3_buckets"
@@ -96,6 +127,15 @@ const (
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
 	QbittorrentSeedtime = "qbittorrent_seedtime"
+
+	// yt-dlp
+	YtdlpPath          = "ytdlp_path"
+	YtdlpDefaultFormat = "ytdlp_default_format"
+	YtdlpArgs          = "ytdlp_args"
+
+	// offline download
+	OfflineDownloadExtractMaxSize    = "offline_download_extract_max_size"
+	OfflineDownloadExtractMaxEntries = "offline_download_extract_max_entries"
 )
 
 const (
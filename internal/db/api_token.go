@@ -0,0 +1,53 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAPIToken(t *model.APIToken) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func GetAPITokenByKey(key string) (*model.APIToken, error) {
+	t := model.APIToken{Key: key}
+	if err := db.Where(t).First(&t).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find api token")
+	}
+	return &t, nil
+}
+
+func GetAPITokenById(id uint) (*model.APIToken, error) {
+	var t model.APIToken
+	if err := db.First(&t, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &t, nil
+}
+
+// GetAPITokens return the tokens of the kind of the user, or of all users if userId is 0
+func GetAPITokens(userId uint, kind string) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	tokenDB := db.Model(&model.APIToken{}).Where("kind = ?", kind)
+	if userId != 0 {
+		tokenDB = tokenDB.Where("user_id = ?", userId)
+	}
+	if err := tokenDB.Order(columnName("id")).Find(&tokens).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find api tokens")
+	}
+	return tokens, nil
+}
+
+func DeleteAPITokenById(id uint) error {
+	return errors.WithStack(db.Delete(&model.APIToken{}, id).Error)
+}
+
+func DeleteAPITokensByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.APIToken{}).Error)
+}
+
+func UpdateAPITokenLastUsed(id uint, t time.Time) error {
+	return errors.WithStack(db.Model(&model.APIToken{}).Where("id = ?", id).UpdateColumn("last_used", t).Error)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetAllStorages() ([]model.Storage, error) {
+	var storages []model.Storage
+	if err := addStorageOrder(db).Find(&storages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find storages")
+	}
+	return storages, nil
+}
+
+func GetAllUsers() ([]model.User, error) {
+	var users []model.User
+	if err := db.Order(columnName("id")).Find(&users).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find users")
+	}
+	return users, nil
+}
+
+func GetAllMetas() ([]model.Meta, error) {
+	var metas []model.Meta
+	if err := db.Order(columnName("id")).Find(&metas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find metas")
+	}
+	return metas, nil
+}
+
+func GetTaskItems() ([]model.TaskItem, error) {
+	var items []model.TaskItem
+	if err := db.Find(&items).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find task items")
+	}
+	return items, nil
+}
+
+// ReplaceConfig replaces all the storages, users, metas and task data in one transaction, ids are kept.
+// the rows bound to the users are dropped as the ids of their users may refer to other users
+func ReplaceConfig(storages []model.Storage, users []model.User, metas []model.Meta, tasks []model.TaskItem) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		userLinked := []any{&model.Session{}, &model.APIToken{}, &model.SignedLink{}, &model.RecoveryCode{},
+			&model.LoginLock{}, &model.ScimGroupMember{}, &model.ScimGroup{}}
+		for _, m := range userLinked {
+			if err := tx.Where("1 = 1").Delete(m).Error; err != nil {
+				return errors.Wrapf(err, "failed delete %T", m)
+			}
+		}
+		if err := replaceTable(tx, &model.Storage{}, storages, true); err != nil {
+			return errors.WithMessage(err, "failed replace storages")
+		}
+		if err := replaceTable(tx, &model.User{}, users, true); err != nil {
+			return errors.WithMessage(err, "failed replace users")
+		}
+		if err := replaceTable(tx, &model.Meta{}, metas, true); err != nil {
+			return errors.WithMessage(err, "failed replace metas")
+		}
+		if err := replaceTable(tx, &model.TaskItem{}, tasks, false); err != nil {
+			return errors.WithMessage(err, "failed replace task items")
+		}
+		return nil
+	})
+}
+
+func replaceTable[T any](tx *gorm.DB, m *T, rows []T, serial bool) error {
+	if err := tx.Where("1 = 1").Delete(m).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(rows, 100).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	// rows are inserted with explicit ids, which doesn't advance the sequence of postgres
+	if serial && conf.Conf.Database.Type == "postgres" {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(m); err != nil {
+			return errors.WithStack(err)
+		}
+		table := stmt.Schema.Table
+		err := tx.Exec(fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', 'id'), (SELECT MAX("id") FROM "%s"))`, table, table)).Error
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.Feed), new(model.FeedItem), new(model.SignedLink), new(model.APIToken), new(model.Session), new(model.ScimGroup), new(model.ScimGroupMember), new(model.LoginAttempt), new(model.LoginLock), new(model.RecoveryCode), new(model.StorageCheck))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetFeedById(id uint) (*model.Feed, error) {
+	var f model.Feed
+	if err := db.First(&f, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get feed")
+	}
+	return &f, nil
+}
+
+func CreateFeed(f *model.Feed) error {
+	return errors.WithStack(db.Create(f).Error)
+}
+
+func UpdateFeed(f *model.Feed) error {
+	return errors.WithStack(db.Save(f).Error)
+}
+
+// UpdateFeedStatus updates only the result of a refresh, so the edits made during the refresh are kept
+func UpdateFeedStatus(id uint, lastFetched time.Time, status string) error {
+	return errors.WithStack(db.Model(&model.Feed{}).Where("id = ?", id).Updates(map[string]any{
+		"last_fetched": lastFetched,
+		"status":       status,
+	}).Error)
+}
+
+func GetFeeds(pageIndex, pageSize int) (feeds []model.Feed, count int64, err error) {
+	feedDB := db.Model(&model.Feed{})
+	if err = feedDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get feeds count")
+	}
+	if err = feedDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&feeds).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find feeds")
+	}
+	return feeds, count, nil
+}
+
+func GetEnabledFeeds() ([]model.Feed, error) {
+	var feeds []model.Feed
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("disabled")), false).Find(&feeds).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return feeds, nil
+}
+
+func DeleteFeedById(id uint) error {
+	if err := db.Where("feed_id = ?", id).Delete(&model.FeedItem{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.Feed{}, id).Error)
+}
+
+func FeedItemExists(feedId uint, guid string) (bool, error) {
+	var count int64
+	if err := db.Model(&model.FeedItem{}).Where("feed_id = ? AND guid = ?", feedId, guid).Count(&count).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+	return count > 0, nil
+}
+
+func CreateFeedItem(i *model.FeedItem) error {
+	return errors.WithStack(db.Create(i).Error)
+}
+
+func DeleteFeedItem(feedId uint, guid string) error {
+	return errors.WithStack(db.Where("feed_id = ? AND guid = ?", feedId, guid).Delete(&model.FeedItem{}).Error)
+}
+
+func GetFeedItems(feedId uint, pageIndex, pageSize int) (items []model.FeedItem, count int64, err error) {
+	itemDB := db.Model(&model.FeedItem{}).Where("feed_id = ?", feedId)
+	if err = itemDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get feed items count")
+	}
+	if err = itemDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find feed items")
+	}
+	return items, count, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateLoginAttempt(a *model.LoginAttempt) error {
+	return errors.WithStack(db.Create(a).Error)
+}
+
+// GetLoginAttempts returns the login history filtered by the non-empty arguments, the latest first
+func GetLoginAttempts(username, ip string, success *bool, pageIndex, pageSize int) (attempts []model.LoginAttempt, count int64, err error) {
+	tx := db.Model(&model.LoginAttempt{})
+	if username != "" {
+		tx = tx.Where("username = ?", username)
+	}
+	if ip != "" {
+		tx = tx.Where("ip = ?", ip)
+	}
+	if success != nil {
+		tx = tx.Where("success = ?", *success)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get login attempts count")
+	}
+	if err := tx.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&attempts).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find login attempts")
+	}
+	return attempts, count, nil
+}
+
+func DeleteLoginAttemptsBefore(t time.Time) error {
+	return errors.WithStack(db.Where("created_at < ?", t).Delete(&model.LoginAttempt{}).Error)
+}
+
+func GetLoginLock(key string) (*model.LoginLock, error) {
+	l := model.LoginLock{Key: key}
+	if err := db.Where(l).First(&l).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find login lock")
+	}
+	return &l, nil
+}
+
+// GetLoginLocks returns the ips and accounts having failures or locked at now
+func GetLoginLocks(now int64) ([]model.LoginLock, error) {
+	var locks []model.LoginLock
+	if err := db.Where("failures > 0 OR locked_until > ?", now).Order("last_failure desc").Find(&locks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find login locks")
+	}
+	return locks, nil
+}
+
+func SaveLoginLock(l *model.LoginLock) error {
+	return errors.WithStack(db.Save(l).Error)
+}
+
+func DeleteLoginLock(key string) error {
+	return errors.WithStack(db.Delete(&model.LoginLock{Key: key}).Error)
+}
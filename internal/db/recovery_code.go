@@ -0,0 +1,47 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetRecoveryCodes(userId uint) ([]model.RecoveryCode, error) {
+	var codes []model.RecoveryCode
+	if err := db.Where("user_id = ?", userId).Find(&codes).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find recovery codes")
+	}
+	return codes, nil
+}
+
+// ReplaceRecoveryCodes deletes the old codes of the user and saves the new ones
+func ReplaceRecoveryCodes(userId uint, codes []model.RecoveryCode) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return errors.WithStack(tx.Create(&codes).Error)
+	})
+}
+
+func DeleteRecoveryCodeById(id uint) error {
+	return errors.WithStack(db.Delete(&model.RecoveryCode{}, id).Error)
+}
+
+func DeleteRecoveryCodesByUserId(userId uint) error {
+	return ReplaceRecoveryCodes(userId, nil)
+}
+
+// SetMfaEnrollBy sets the enrolment deadline of the user if it is not set yet
+func SetMfaEnrollBy(userId uint, deadline int64) error {
+	return errors.WithStack(db.Model(&model.User{}).Where("id = ? AND mfa_enroll_by = 0", userId).
+		Update("mfa_enroll_by", deadline).Error)
+}
+
+// ResetMfaEnrollBy clears the enrolment deadlines which are not passed yet at now
+func ResetMfaEnrollBy(now int64) error {
+	return errors.WithStack(db.Model(&model.User{}).Where("mfa_enroll_by > ?", now).Update("mfa_enroll_by", 0).Error)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetScimGroups() ([]model.ScimGroup, error) {
+	var groups []model.ScimGroup
+	if err := db.Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find scim groups")
+	}
+	return groups, nil
+}
+
+func GetScimGroupById(id uint) (*model.ScimGroup, error) {
+	var g model.ScimGroup
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get scim group")
+	}
+	return &g, nil
+}
+
+func CreateScimGroup(g *model.ScimGroup) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateScimGroup(g *model.ScimGroup) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func DeleteScimGroupById(id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.ScimGroupMember{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(tx.Delete(&model.ScimGroup{}, id).Error)
+	})
+}
+
+// GetScimGroupMembers returns the ids of the members of the group
+func GetScimGroupMembers(groupId uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Model(&model.ScimGroupMember{}).Where("group_id = ?", groupId).
+		Order("user_id").Pluck("user_id", &ids).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get scim group members")
+	}
+	return ids, nil
+}
+
+// SetScimGroupMembers replaces the members of the group
+func SetScimGroupMembers(groupId uint, userIds []uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupId).Delete(&model.ScimGroupMember{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(userIds) == 0 {
+			return nil
+		}
+		members := make([]model.ScimGroupMember, 0, len(userIds))
+		for _, id := range userIds {
+			members = append(members, model.ScimGroupMember{GroupID: groupId, UserID: id})
+		}
+		return errors.WithStack(tx.Create(&members).Error)
+	})
+}
+
+// GetUserScimGroups returns the scim groups the user is a member of
+func GetUserScimGroups(userId uint) ([]model.ScimGroup, error) {
+	var groups []model.ScimGroup
+	sub := db.Model(&model.ScimGroupMember{}).Select("group_id").Where("user_id = ?", userId)
+	if err := db.Where("id IN (?)", sub).Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user scim groups")
+	}
+	return groups, nil
+}
+
+func DeleteScimGroupMembersByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.ScimGroupMember{}).Error)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateSession(s *model.Session) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func GetSessionBySessionId(sessionId string) (*model.Session, error) {
+	s := model.Session{SessionID: sessionId}
+	if err := db.Where(s).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find session")
+	}
+	return &s, nil
+}
+
+func GetSessionsByUserId(userId uint) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := db.Where("user_id = ? AND expire >= ?", userId, time.Now().Unix()).
+		Order("last_seen desc").Find(&sessions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sessions")
+	}
+	return sessions, nil
+}
+
+func UpdateSession(s *model.Session) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+// RotateSession updates the session only if its refresh hash is still oldHash,
+// false is returned if the refresh token has been rotated by another request
+func RotateSession(s *model.Session, oldHash string) (bool, error) {
+	res := db.Model(&model.Session{}).Where("id = ? AND refresh_hash = ?", s.ID, oldHash).
+		UpdateColumns(map[string]any{
+			"refresh_hash":      s.RefreshHash,
+			"prev_refresh_hash": s.PrevRefreshHash,
+			"expire":            s.Expire,
+			"last_seen":         s.LastSeen,
+		})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func UpdateSessionLastSeen(id uint, ip string, t time.Time) error {
+	return errors.WithStack(db.Model(&model.Session{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"ip": ip, "last_seen": t}).Error)
+}
+
+func DeleteSessionBySessionId(sessionId string) error {
+	return errors.WithStack(db.Where("session_id = ?", sessionId).Delete(&model.Session{}).Error)
+}
+
+func DeleteSessionsByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.Session{}).Error)
+}
+
+func DeleteExpiredSessions() error {
+	return errors.WithStack(db.Where("expire < ?", time.Now().Unix()).Delete(&model.Session{}).Error)
+}
+
+func GetSSOSessions(provider, subject, sid string) ([]model.Session, error) {
+	var sessions []model.Session
+	sessionDB := db.Where("sso_provider = ?", provider)
+	if sid != "" {
+		sessionDB = sessionDB.Where("sso_sid = ?", sid)
+	} else {
+		sessionDB = sessionDB.Where("sso_subject = ?", subject)
+	}
+	if err := sessionDB.Find(&sessions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sso sessions")
+	}
+	return sessions, nil
+}
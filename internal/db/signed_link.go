@@ -0,0 +1,57 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateSignedLink(l *model.SignedLink) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func GetSignedLinkByKey(key string) (*model.SignedLink, error) {
+	l := model.SignedLink{Key: key}
+	if err := db.Where(l).First(&l).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find signed link")
+	}
+	return &l, nil
+}
+
+func GetSignedLinkById(id uint) (*model.SignedLink, error) {
+	var l model.SignedLink
+	if err := db.First(&l, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get signed link")
+	}
+	return &l, nil
+}
+
+// GetSignedLinks return the links of the user, or of all users if userId is 0
+func GetSignedLinks(userId uint, pageIndex, pageSize int) (links []model.SignedLink, count int64, err error) {
+	linkDB := db.Model(&model.SignedLink{})
+	if userId != 0 {
+		linkDB = linkDB.Where("user_id = ?", userId)
+	}
+	if err = linkDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get signed links count")
+	}
+	if err = linkDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&links).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find signed links")
+	}
+	return links, count, nil
+}
+
+func DeleteSignedLinkById(id uint) error {
+	return errors.WithStack(db.Delete(&model.SignedLink{}, id).Error)
+}
+
+// UseSignedLink increases the use count of the link, return false if the max use count has been reached
+func UseSignedLink(id uint) (bool, error) {
+	res := db.Model(&model.SignedLink{}).
+		Where("id = ? AND (max_uses = 0 OR uses < max_uses)", id).
+		UpdateColumn("uses", gorm.Expr("uses + ?", 1))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
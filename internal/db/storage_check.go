@@ -0,0 +1,35 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateStorageCheck(c *model.StorageCheck) error {
+	return errors.WithStack(db.Create(c).Error)
+}
+
+// GetStorageChecks returns the check history of the storage, or of all storages if storageId is 0, the latest first
+func GetStorageChecks(storageId uint, pageIndex, pageSize int) (checks []model.StorageCheck, count int64, err error) {
+	tx := db.Model(&model.StorageCheck{})
+	if storageId != 0 {
+		tx = tx.Where("storage_id = ?", storageId)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get storage checks count")
+	}
+	if err := tx.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&checks).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find storage checks")
+	}
+	return checks, count, nil
+}
+
+func DeleteStorageChecksBefore(t time.Time) error {
+	return errors.WithStack(db.Where("created_at < ?", t).Delete(&model.StorageCheck{}).Error)
+}
+
+func DeleteStorageChecksByStorageId(storageId uint) error {
+	return errors.WithStack(db.Where("storage_id = ?", storageId).Delete(&model.StorageCheck{}).Error)
+}
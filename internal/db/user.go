@@ -100,3 +100,12 @@ func RemoveAuthn(u *model.User, id string) error {
 	}
 	return UpdateAuthn(u.ID, string(res))
 }
+
+// GetLdapUsers returns the users managed by ldap
+func GetLdapUsers() ([]model.User, error) {
+	var users []model.User
+	if err := db.Where("ldap_dn <> ?", "").Find(&users).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find ldap users")
+	}
+	return users, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type Driver interface {
@@ -109,6 +110,41 @@ type PutResult interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) (model.Obj, error)
 }
 
+// HealthChecker is implemented by the drivers which have a cheaper way to check the storage than listing the root
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// SpaceInfo is implemented by the drivers which can report the space of the storage
+type SpaceInfo interface {
+	GetSpace(ctx context.Context) (*model.Space, error)
+}
+
+// CrossStorage is implemented by the drivers which can copy or move objects to another storage on the server side
+type CrossStorage interface {
+	// SameAccount reports whether the dst storage shares the account or endpoint with the storage
+	SameAccount(dst Driver) bool
+}
+
+// CrossStorageCopier copies the object to the dir of the dst storage of the same account
+type CrossStorageCopier interface {
+	CrossStorage
+	CopyTo(ctx context.Context, srcObj model.Obj, dst Driver, dstDir model.Obj) error
+}
+
+// CrossStorageMover moves the object to the dir of the dst storage of the same account
+type CrossStorageMover interface {
+	CrossStorage
+	MoveTo(ctx context.Context, srcObj model.Obj, dst Driver, dstDir model.Obj) error
+}
+
+// RapidUpload is implemented by the drivers which upload a file instantly if the cloud already has it,
+// the stream should carry the hashes of the types to do so
+type RapidUpload interface {
+	// RapidUploadHash returns the hash types needed by the rapid upload, nil if it's disabled
+	RapidUploadHash() []*utils.HashType
+}
+
 type UpdateProgress func(percentage float64)
 
 type Progress struct {
@@ -0,0 +1,438 @@
+// Package drivertest is a conformance suite of the drivers, it exercises a driver through op as alist does,
+// so that the contracts of the driver interfaces are checked, e.g. the ids and paths of the objects,
+// overwriting on upload and ranged reads of the links
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
+)
+
+// WorkDir is the folder created under the root of the storage for the tests
+const WorkDir = "/drivertest"
+
+type Options struct {
+	// Stateless is for fake drivers which don't persist the writes, such as Virtual,
+	// only the results of the operations are checked
+	Stateless bool
+}
+
+// Init sets the storage of the driver and initializes it, the addition should be set in the driver before,
+// the driver is dropped when the test finishes
+func Init(t *testing.T, d driver.Driver) {
+	t.Helper()
+	name := d.Config().Name
+	d.SetStorage(model.Storage{
+		MountPath: "/drivertest_" + strings.ToLower(name),
+		Driver:    name,
+		Modified:  time.Now(),
+	})
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatalf("failed init %s: %+v", name, err)
+	}
+	d.GetStorage().SetStatus(op.WORK)
+	t.Cleanup(func() {
+		_ = d.Drop(context.Background())
+	})
+}
+
+// Run runs the conformance tests against the initialized driver
+func Run(t *testing.T, d driver.Driver, opts Options) {
+	if opts.Stateless {
+		runStateless(t, d)
+		return
+	}
+	runStateful(t, d)
+}
+
+// Content returns deterministic content of the size, seed makes different contents of the same size
+func Content(size int, seed byte) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i*31) + seed
+	}
+	return b
+}
+
+// Put uploads the data as a file named name to the dir
+func Put(ctx context.Context, d driver.Driver, dir, name string, data []byte) error {
+	file := &stream.FileStream{
+		Ctx: ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     int64(len(data)),
+			Modified: time.Now(),
+		},
+		Reader:   bytes.NewReader(data),
+		Mimetype: "application/octet-stream",
+	}
+	return op.Put(ctx, d, dir, file, nil)
+}
+
+// Read reads the range of the file by its link, a length of -1 means to the end
+func Read(ctx context.Context, d driver.Driver, path string, r http_range.Range) ([]byte, error) {
+	link, obj, err := op.Link(ctx, d, path, model.LinkArgs{Header: http.Header{}})
+	if err != nil {
+		return nil, err
+	}
+	return readLink(ctx, obj, link, r)
+}
+
+func readLink(ctx context.Context, obj model.Obj, link *model.Link, r http_range.Range) ([]byte, error) {
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.Close()
+	if r.Length == -1 {
+		r.Length = obj.GetSize() - r.Start
+	}
+	reader, err := ss.RangeRead(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// List lists the dir without cache and returns the objects by name
+func List(ctx context.Context, d driver.Driver, dir string) (map[string]model.Obj, error) {
+	objs, err := op.List(ctx, d, dir, model.ListArgs{Refresh: true})
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]model.Obj, len(objs))
+	for _, obj := range objs {
+		res[obj.GetName()] = obj
+	}
+	return res, nil
+}
+
+func unsupported(err error) bool {
+	return errors.Is(err, errs.NotImplement) || errors.Is(err, errs.NotSupport)
+}
+
+func mustList(t *testing.T, d driver.Driver, dir string) map[string]model.Obj {
+	t.Helper()
+	objs, err := List(context.Background(), d, dir)
+	if err != nil {
+		t.Fatalf("failed list %s: %+v", dir, err)
+	}
+	return objs
+}
+
+func assertFile(t *testing.T, d driver.Driver, dir, name string, data []byte) {
+	t.Helper()
+	obj, ok := mustList(t, d, dir)[name]
+	if !ok {
+		t.Fatalf("%s not found in %s", name, dir)
+	}
+	if obj.IsDir() {
+		t.Fatalf("%s is expected to be a file", name)
+	}
+	if obj.GetSize() != int64(len(data)) {
+		t.Errorf("size of %s: expected %d, got %d", name, len(data), obj.GetSize())
+	}
+	got, err := Read(context.Background(), d, stdpath.Join(dir, name), http_range.Range{Length: -1})
+	if err != nil {
+		t.Fatalf("failed read %s: %+v", name, err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("content of %s is not the uploaded one", name)
+	}
+}
+
+func assertAbsent(t *testing.T, d driver.Driver, dir, name string) {
+	t.Helper()
+	if _, ok := mustList(t, d, dir)[name]; ok {
+		t.Errorf("%s is expected to be absent in %s", name, dir)
+	}
+}
+
+// noOverwrite makes op.Put replace an existing file by renaming it first and removing it after the upload,
+// as it does for the drivers which can't overwrite
+type noOverwrite struct {
+	driver.Driver
+}
+
+func (d noOverwrite) Config() driver.Config {
+	config := d.Driver.Config()
+	config.NoOverwriteUpload = true
+	return config
+}
+
+func (d noOverwrite) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	switch s := d.Driver.(type) {
+	case driver.Mkdir:
+		return s.MakeDir(ctx, parentDir, dirName)
+	case driver.MkdirResult:
+		_, err := s.MakeDir(ctx, parentDir, dirName)
+		return err
+	}
+	return errs.NotImplement
+}
+
+func (d noOverwrite) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	switch s := d.Driver.(type) {
+	case driver.Rename:
+		return s.Rename(ctx, srcObj, newName)
+	case driver.RenameResult:
+		_, err := s.Rename(ctx, srcObj, newName)
+		return err
+	}
+	return errs.NotImplement
+}
+
+func (d noOverwrite) Remove(ctx context.Context, obj model.Obj) error {
+	if s, ok := d.Driver.(driver.Remove); ok {
+		return s.Remove(ctx, obj)
+	}
+	return errs.NotImplement
+}
+
+func (d noOverwrite) Put(ctx context.Context, dstDir model.Obj, file model.FileStreamer, up driver.UpdateProgress) error {
+	switch s := d.Driver.(type) {
+	case driver.Put:
+		return s.Put(ctx, dstDir, file, up)
+	case driver.PutResult:
+		_, err := s.Put(ctx, dstDir, file, up)
+		return err
+	}
+	return errs.NotImplement
+}
+
+// noOverwriteGetter is noOverwrite of a driver.Getter, which may get the root only by Get
+type noOverwriteGetter struct {
+	noOverwrite
+}
+
+func (d noOverwriteGetter) Get(ctx context.Context, path string) (model.Obj, error) {
+	return d.Driver.(driver.Getter).Get(ctx, path)
+}
+
+func runStateful(t *testing.T, d driver.Driver) {
+	ctx := context.Background()
+	dir := stdpath.Join(WorkDir, "dir")
+	first, second := Content(4096, 1), Content(2048, 2)
+	// a leftover of a failed run
+	_ = op.Remove(ctx, d, WorkDir)
+	t.Cleanup(func() {
+		_ = op.Remove(ctx, d, WorkDir)
+	})
+
+	if !t.Run("MakeDir", func(t *testing.T) {
+		if err := op.MakeDir(ctx, d, dir); err != nil {
+			t.Fatalf("failed make dir: %+v", err)
+		}
+		obj, ok := mustList(t, d, WorkDir)["dir"]
+		if !ok || !obj.IsDir() {
+			t.Fatalf("dir is expected to be a folder in %s", WorkDir)
+		}
+		// making an existing dir is not an error
+		if err := op.MakeDir(ctx, d, dir); err != nil {
+			t.Errorf("failed make existing dir: %+v", err)
+		}
+	}) {
+		return
+	}
+
+	if !t.Run("Put", func(t *testing.T) {
+		if err := Put(ctx, d, WorkDir, "file.bin", first); err != nil {
+			t.Fatalf("failed put: %+v", err)
+		}
+		assertFile(t, d, WorkDir, "file.bin", first)
+	}) {
+		return
+	}
+
+	t.Run("Objects", func(t *testing.T) {
+		for name, obj := range mustList(t, d, WorkDir) {
+			unwrapped := model.UnwrapObj(obj)
+			if unwrapped.GetPath() == "" && unwrapped.GetID() == "" {
+				t.Errorf("%s has neither path nor id to be identified", name)
+			}
+			if p := unwrapped.GetPath(); p != "" && stdpath.Base(p) != name {
+				t.Errorf("path of %s is expected to end with its name, got %s", name, p)
+			}
+			g, ok := d.(driver.Getter)
+			if !ok {
+				continue
+			}
+			got, err := g.Get(ctx, stdpath.Join(WorkDir, name))
+			if err != nil {
+				t.Errorf("failed get %s: %+v", name, err)
+				continue
+			}
+			if got.GetName() != name || got.IsDir() != obj.IsDir() || (!obj.IsDir() && got.GetSize() != obj.GetSize()) {
+				t.Errorf("get %s: expected the listed %+v, got %+v", name, obj, got)
+			}
+			if id := unwrapped.GetID(); id != "" && got.GetID() != id {
+				t.Errorf("id of %s: expected %s, got %s", name, id, got.GetID())
+			}
+		}
+		if _, err := op.Get(ctx, d, stdpath.Join(WorkDir, "not_exist.bin")); !errs.IsObjectNotFound(err) {
+			t.Errorf("expected object not found, got %+v", err)
+		}
+	})
+
+	t.Run("RangedLink", func(t *testing.T) {
+		for _, r := range []http_range.Range{{Start: 0, Length: 1}, {Start: 100, Length: 1000}, {Start: 4000, Length: 96}, {Start: 4090, Length: -1}} {
+			got, err := Read(ctx, d, stdpath.Join(WorkDir, "file.bin"), r)
+			if err != nil {
+				t.Fatalf("failed read %+v: %+v", r, err)
+			}
+			end := int64(len(first))
+			if r.Length != -1 {
+				end = r.Start + r.Length
+			}
+			if !bytes.Equal(got, first[r.Start:end]) {
+				t.Errorf("read %+v: got %d bytes not matching", r, len(got))
+			}
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		if err := Put(ctx, d, WorkDir, "file.bin", second); err != nil {
+			t.Fatalf("failed overwrite: %+v", err)
+		}
+		assertFile(t, d, WorkDir, "file.bin", second)
+		// the file is replaced, not duplicated
+		objs, err := op.List(ctx, d, WorkDir, model.ListArgs{Refresh: true})
+		if err != nil {
+			t.Fatalf("failed list: %+v", err)
+		}
+		if len(objs) != 2 {
+			t.Errorf("expected dir and file.bin in %s, got %d objects", WorkDir, len(objs))
+		}
+	})
+
+	t.Run("NoOverwriteUpload", func(t *testing.T) {
+		third := Content(3000, 3)
+		var w driver.Driver = noOverwrite{d}
+		if _, ok := d.(driver.Getter); ok {
+			w = noOverwriteGetter{noOverwrite{d}}
+		}
+		if err := Put(ctx, w, WorkDir, "file.bin", third); err != nil {
+			if unsupported(err) {
+				t.Skip("rename is not supported")
+			}
+			t.Fatalf("failed overwrite: %+v", err)
+		}
+		assertFile(t, d, WorkDir, "file.bin", third)
+		assertAbsent(t, d, WorkDir, "file.bin.alist_to_delete")
+		// back to the content expected by the following tests
+		if err := Put(ctx, d, WorkDir, "file.bin", second); err != nil {
+			t.Fatalf("failed overwrite: %+v", err)
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		err := op.Rename(ctx, d, stdpath.Join(WorkDir, "file.bin"), "renamed.bin")
+		if unsupported(err) {
+			t.Skip("rename is not supported")
+		}
+		if err != nil {
+			t.Fatalf("failed rename: %+v", err)
+		}
+		assertAbsent(t, d, WorkDir, "file.bin")
+		assertFile(t, d, WorkDir, "renamed.bin", second)
+		// the following tests work on renamed.bin
+	})
+	if _, err := op.Get(ctx, d, stdpath.Join(WorkDir, "renamed.bin")); err != nil {
+		if err := Put(ctx, d, WorkDir, "renamed.bin", second); err != nil {
+			t.Fatalf("failed put: %+v", err)
+		}
+	}
+
+	t.Run("Move", func(t *testing.T) {
+		err := op.Move(ctx, d, stdpath.Join(WorkDir, "renamed.bin"), dir)
+		if unsupported(err) {
+			t.Skip("move is not supported")
+		}
+		if err != nil {
+			t.Fatalf("failed move: %+v", err)
+		}
+		assertAbsent(t, d, WorkDir, "renamed.bin")
+		assertFile(t, d, dir, "renamed.bin", second)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		src := stdpath.Join(dir, "renamed.bin")
+		if _, err := op.Get(ctx, d, src); err != nil {
+			t.Skip("nothing to copy as move failed")
+		}
+		err := op.Copy(ctx, d, src, WorkDir)
+		if unsupported(err) {
+			t.Skip("copy is not supported")
+		}
+		if err != nil {
+			t.Fatalf("failed copy: %+v", err)
+		}
+		assertFile(t, d, dir, "renamed.bin", second)
+		assertFile(t, d, WorkDir, "renamed.bin", second)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if err := op.Remove(ctx, d, WorkDir); err != nil {
+			t.Fatalf("failed remove: %+v", err)
+		}
+		assertAbsent(t, d, "/", stdpath.Base(WorkDir))
+		if err := op.Remove(ctx, d, WorkDir); err != nil {
+			t.Errorf("removing an absent object is expected to be ignored: %+v", err)
+		}
+	})
+}
+
+func runStateless(t *testing.T, d driver.Driver) {
+	ctx := context.Background()
+	var file model.Obj
+	t.Run("List", func(t *testing.T) {
+		for name, obj := range mustList(t, d, "/") {
+			if name == "" {
+				t.Errorf("object without name: %+v", obj)
+			}
+			if !obj.IsDir() && file == nil {
+				file = obj
+			}
+		}
+	})
+	t.Run("RangedLink", func(t *testing.T) {
+		if file == nil {
+			t.Skip("no file to read")
+		}
+		// the listing is random, so the listed object is linked directly
+		link, err := d.Link(ctx, model.UnwrapObj(file), model.LinkArgs{Header: http.Header{}})
+		if err != nil {
+			t.Fatalf("failed link: %+v", err)
+		}
+		length := min(file.GetSize(), 64)
+		got, err := readLink(ctx, file, link, http_range.Range{Start: 0, Length: length})
+		if err != nil {
+			t.Fatalf("failed read: %+v", err)
+		}
+		if int64(len(got)) != length {
+			t.Errorf("expected %d bytes, got %d", length, len(got))
+		}
+	})
+	t.Run("Write", func(t *testing.T) {
+		if err := op.MakeDir(ctx, d, WorkDir); err != nil && !unsupported(err) {
+			t.Errorf("failed make dir: %+v", err)
+		}
+		if err := Put(ctx, d, "/", "file.bin", Content(16, 0)); err != nil && !unsupported(err) {
+			t.Errorf("failed put: %+v", err)
+		}
+	})
+}
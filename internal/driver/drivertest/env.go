@@ -0,0 +1,45 @@
+package drivertest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// InitDB initializes the config and an in-memory database, for the tests which create storages
+func InitDB() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	conf.Conf.TempDir = os.TempDir()
+	db.Init(dB)
+}
+
+// NewLocalStorage mounts a temp dir at the mount path by a Local storage, which is removed after the test,
+// the temp dir is returned. the Local driver should be imported by the test
+func NewLocalStorage(t *testing.T, mountPath string) string {
+	ctx := context.Background()
+	root := t.TempDir()
+	id, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteStorageById(ctx, id)
+	})
+	return root
+}
@@ -0,0 +1,9 @@
+package errs
+
+import "errors"
+
+var (
+	APITokenInvalid = errors.New("api token is invalid or revoked")
+	APITokenExpired = errors.New("api token expired")
+	APITokenScope   = errors.New("api token is not granted the scope")
+)
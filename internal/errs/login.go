@@ -0,0 +1,15 @@
+package errs
+
+import "errors"
+
+var (
+	LoginLocked     = errors.New("too many unsuccessful sign-in attempts have been made, try again later")
+	CaptchaRequired = errors.New("captcha is required")
+	CaptchaInvalid  = errors.New("captcha is invalid")
+
+	MfaRequired         = errors.New("2FA is required by the administrator, enroll it please")
+	RecoveryCodeInvalid = errors.New("recovery code is invalid")
+	OtpCodeInvalid      = errors.New("invalid 2FA code")
+	WebAuthnRequired    = errors.New("login with webauthn or a recovery code please")
+	IPDenied            = errors.New("access from your ip is denied")
+)
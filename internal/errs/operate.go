@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	PermissionDenied = errors.New("permission denied")
+	StorageReadOnly  = errors.New("storage is read-only")
 )
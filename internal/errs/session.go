@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	SessionNotFound = errors.New("session is revoked or expired, login please")
+	RefreshInvalid  = errors.New("refresh token is invalid")
+)
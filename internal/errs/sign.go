@@ -0,0 +1,11 @@
+package errs
+
+import "errors"
+
+var (
+	SignedLinkNotFound      = errors.New("signed link not found or revoked")
+	SignedLinkExpired       = errors.New("signed link expired")
+	SignedLinkExhausted     = errors.New("signed link has reached its max use count")
+	SignedLinkIPDenied      = errors.New("signed link is not allowed from this ip")
+	SignedLinkRefererDenied = errors.New("signed link is not allowed from this referer")
+)
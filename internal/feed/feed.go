@@ -0,0 +1,242 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultInterval = 30
+
+var (
+	crons  generic_sync.MapOf[uint, *cron.Cron]
+	client = &http.Client{Timeout: time.Minute}
+
+	// locks serializes the refreshes of a feed by the cron and by hand
+	locks generic_sync.MapOf[uint, *sync.Mutex]
+)
+
+// Init schedules all the enabled feeds
+func Init() {
+	feeds, err := db.GetEnabledFeeds()
+	if err != nil {
+		utils.Log.Errorf("failed get enabled feeds: %+v", err)
+		return
+	}
+	for i := range feeds {
+		schedule(feeds[i])
+	}
+}
+
+func schedule(f model.Feed) {
+	unschedule(f.ID)
+	if f.Disabled {
+		return
+	}
+	interval := f.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	c := cron.NewCron(time.Minute * time.Duration(interval))
+	c.Do(func() {
+		feed, err := db.GetFeedById(f.ID)
+		if err != nil {
+			log.Errorf("failed get feed %d: %+v", f.ID, err)
+			return
+		}
+		if _, err = Refresh(context.Background(), feed); err != nil {
+			log.Errorf("failed refresh feed [%s]: %+v", feed.Name, err)
+		}
+	})
+	crons.Store(f.ID, c)
+}
+
+func unschedule(id uint) {
+	if c, ok := crons.Load(id); ok {
+		c.Stop()
+		crons.Delete(id)
+	}
+}
+
+func validate(f *model.Feed) error {
+	if _, err := regexp.Compile(f.Include); err != nil {
+		return errors.Wrap(err, "invalid include regex")
+	}
+	if _, err := regexp.Compile(f.Exclude); err != nil {
+		return errors.Wrap(err, "invalid exclude regex")
+	}
+	if _, err := tool.Tools.Get(f.Tool); err != nil {
+		return err
+	}
+	f.DstDirPath = utils.FixAndCleanPath(f.DstDirPath)
+	return nil
+}
+
+func CreateFeed(f *model.Feed) error {
+	if err := validate(f); err != nil {
+		return err
+	}
+	if err := db.CreateFeed(f); err != nil {
+		return err
+	}
+	schedule(*f)
+	return nil
+}
+
+func UpdateFeed(f *model.Feed) error {
+	if err := validate(f); err != nil {
+		return err
+	}
+	old, err := db.GetFeedById(f.ID)
+	if err != nil {
+		return err
+	}
+	f.LastFetched, f.Status = old.LastFetched, old.Status
+	if err := db.UpdateFeed(f); err != nil {
+		return err
+	}
+	schedule(*f)
+	return nil
+}
+
+func DeleteFeedById(id uint) error {
+	unschedule(id)
+	locks.Delete(id)
+	return db.DeleteFeedById(id)
+}
+
+// Refresh fetches the feed and adds an offline download task for each new item that passes the filters,
+// return the number of added tasks
+func Refresh(ctx context.Context, f *model.Feed) (int, error) {
+	lock, _ := locks.LoadOrStore(f.ID, &sync.Mutex{})
+	lock.Lock()
+	defer lock.Unlock()
+	n, err := refresh(ctx, f)
+	f.LastFetched = time.Now()
+	if err != nil {
+		f.Status = err.Error()
+	} else {
+		f.Status = fmt.Sprintf("work, %d new items", n)
+	}
+	if err := db.UpdateFeedStatus(f.ID, f.LastFetched, f.Status); err != nil {
+		log.Errorf("failed update feed [%s]: %+v", f.Name, err)
+	}
+	return n, err
+}
+
+func refresh(ctx context.Context, f *model.Feed) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to fetch feed")
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return 0, errors.Errorf("failed to fetch feed, http status code %d", res.StatusCode)
+	}
+	items, err := Parse(res.Body)
+	if err != nil {
+		return 0, err
+	}
+	match, err := newMatcher(f)
+	if err != nil {
+		return 0, err
+	}
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return 0, err
+	}
+	ctx = context.WithValue(ctx, "user", admin)
+	n := 0
+	for _, item := range items {
+		if item.URL == "" || !match(item) {
+			continue
+		}
+		exists, err := db.FeedItemExists(f.ID, item.GUID)
+		if err != nil {
+			return n, err
+		}
+		if exists {
+			continue
+		}
+		// the item is recorded before enqueued, the unique index of (feed_id, guid) makes sure it's enqueued once
+		err = db.CreateFeedItem(&model.FeedItem{
+			FeedID:    f.ID,
+			GUID:      item.GUID,
+			Title:     item.Title,
+			URL:       item.URL,
+			Size:      item.Size,
+			Published: item.Published,
+		})
+		if err != nil {
+			return n, err
+		}
+		_, err = tool.AddURL(ctx, &tool.AddURLArgs{
+			URL:          item.URL,
+			DstDirPath:   f.DstDirPath,
+			Tool:         f.Tool,
+			DeletePolicy: tool.DeletePolicy(f.DeletePolicy),
+		})
+		if err != nil {
+			// retried by the next refresh
+			if err := db.DeleteFeedItem(f.ID, item.GUID); err != nil {
+				log.Errorf("failed delete feed item [%s]: %+v", item.Title, err)
+			}
+			return n, errors.WithMessagef(err, "failed to add %s", item.Title)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func newMatcher(f *model.Feed) (func(Item) bool, error) {
+	var include, exclude *regexp.Regexp
+	var err error
+	if f.Include != "" {
+		if include, err = regexp.Compile(f.Include); err != nil {
+			return nil, err
+		}
+	}
+	if f.Exclude != "" {
+		if exclude, err = regexp.Compile(f.Exclude); err != nil {
+			return nil, err
+		}
+	}
+	return func(item Item) bool {
+		if include != nil && !include.MatchString(item.Title) {
+			return false
+		}
+		if exclude != nil && exclude.MatchString(item.Title) {
+			return false
+		}
+		if item.Size > 0 {
+			if f.MinSize > 0 && item.Size < f.MinSize {
+				return false
+			}
+			if f.MaxSize > 0 && item.Size > f.MaxSize {
+				return false
+			}
+		}
+		if f.MaxAge > 0 && !item.Published.IsZero() &&
+			time.Since(item.Published) > time.Hour*time.Duration(f.MaxAge) {
+			return false
+		}
+		return true
+	}, nil
+}
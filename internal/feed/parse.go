@@ -0,0 +1,166 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Item is a normalized entry of a RSS or Atom feed
+type Item struct {
+	GUID      string
+	Title     string
+	URL       string
+	Size      int64
+	Published time.Time
+}
+
+type rss struct {
+	Channel struct {
+		Items []struct {
+			GUID      string `xml:"guid"`
+			Title     string `xml:"title"`
+			Link      string `xml:"link"`
+			PubDate   string `xml:"pubDate"`
+			Enclosure struct {
+				URL    string `xml:"url,attr"`
+				Length string `xml:"length,attr"`
+				Type   string `xml:"type,attr"`
+			} `xml:"enclosure"`
+			// torrent feeds, e.g. <torrent:magnetURI> and <torrent:contentLength>
+			MagnetURI     string `xml:"magnetURI"`
+			ContentLength string `xml:"contentLength"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atom struct {
+	Entries []struct {
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+		Links     []struct {
+			Href   string `xml:"href,attr"`
+			Rel    string `xml:"rel,attr"`
+			Type   string `xml:"type,attr"`
+			Length string `xml:"length,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func parseSize(s string) int64 {
+	size, _ := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	return size
+}
+
+// Parse parses a RSS 2.0 or Atom feed
+func Parse(r io.Reader) ([]Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return nil, errors.Wrap(err, "failed to parse feed")
+	}
+	switch root.XMLName.Local {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	default:
+		return nil, errors.Errorf("unknown feed type: %s", root.XMLName.Local)
+	}
+}
+
+func parseRSS(data []byte) ([]Item, error) {
+	var feed rss
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse rss")
+	}
+	items := make([]Item, 0, len(feed.Channel.Items))
+	for _, it := range feed.Channel.Items {
+		item := Item{
+			Title:     strings.TrimSpace(it.Title),
+			Published: parseTime(it.PubDate),
+			Size:      parseSize(it.ContentLength),
+		}
+		// prefer the magnet or the enclosure (.torrent or media file) to the page link
+		switch {
+		case it.MagnetURI != "":
+			item.URL = it.MagnetURI
+		case it.Enclosure.URL != "":
+			item.URL = it.Enclosure.URL
+			if item.Size == 0 {
+				item.Size = parseSize(it.Enclosure.Length)
+			}
+		default:
+			item.URL = strings.TrimSpace(it.Link)
+		}
+		item.GUID = strings.TrimSpace(it.GUID)
+		if item.GUID == "" {
+			item.GUID = item.URL
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseAtom(data []byte) ([]Item, error) {
+	var feed atom
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse atom")
+	}
+	items := make([]Item, 0, len(feed.Entries))
+	for _, e := range feed.Entries {
+		item := Item{
+			GUID:      strings.TrimSpace(e.ID),
+			Title:     strings.TrimSpace(e.Title),
+			Published: parseTime(e.Published),
+		}
+		if item.Published.IsZero() {
+			item.Published = parseTime(e.Updated)
+		}
+		for _, l := range e.Links {
+			if l.Rel == "enclosure" {
+				item.URL = l.Href
+				item.Size = parseSize(l.Length)
+				break
+			}
+			if item.URL == "" && (l.Rel == "" || l.Rel == "alternate") {
+				item.URL = l.Href
+			}
+		}
+		if item.GUID == "" {
+			item.GUID = item.URL
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,60 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torrent="http://xmlns.ezrss.it/0.1/">
+<channel>
+<item>
+	<title>Show S01E01 1080p</title>
+	<link>https://example.com/view/1</link>
+	<guid>https://example.com/view/1</guid>
+	<pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>
+	<enclosure url="https://example.com/1.torrent" length="1024" type="application/x-bittorrent"/>
+</item>
+<item>
+	<title>Show S01E02 1080p</title>
+	<link>https://example.com/view/2</link>
+	<torrent:magnetURI>magnet:?xt=urn:btih:abc</torrent:magnetURI>
+	<torrent:contentLength>2048</torrent:contentLength>
+</item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<entry>
+	<id>urn:talk:1</id>
+	<title>Keynote</title>
+	<updated>2006-01-02T15:04:05Z</updated>
+	<link href="https://example.com/talk/1"/>
+	<link rel="enclosure" href="https://example.com/talk/1.mp4" length="4096"/>
+</entry>
+</feed>`
+
+func TestParse(t *testing.T) {
+	items, err := Parse(strings.NewReader(rssFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expect 2 items, got %d", len(items))
+	}
+	if items[0].URL != "https://example.com/1.torrent" || items[0].Size != 1024 || items[0].Published.IsZero() {
+		t.Errorf("unexpected item: %+v", items[0])
+	}
+	if items[1].URL != "magnet:?xt=urn:btih:abc" || items[1].Size != 2048 || items[1].GUID != items[1].URL {
+		t.Errorf("unexpected item: %+v", items[1])
+	}
+
+	items, err = Parse(strings.NewReader(atomFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].URL != "https://example.com/talk/1.mp4" || items[0].GUID != "urn:talk:1" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
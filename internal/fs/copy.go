@@ -8,6 +8,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -55,11 +56,11 @@ var CopyTaskManager *tache.Manager[*CopyTask]
 // Copy if in the same storage, call move method
 // if not, add copy task
 func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskInfoWithCreator, error) {
-	srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(srcObjPath)
+	srcStorage, srcObjActualPath, err := getStorageAndActualPath(ctx, srcObjPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get src storage")
 	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	dstStorage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
@@ -67,6 +68,11 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
 		return nil, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
 	}
+	// server-side copy between the storages of the same account
+	err = op.CrossStorageCopy(ctx, srcStorage, srcObjActualPath, dstStorage, dstDirActualPath, lazyCache...)
+	if !errors.Is(err, errs.NotSupport) {
+		return nil, err
+	}
 	if ctx.Value(conf.NoTaskKey) != nil {
 		srcObj, err := op.Get(ctx, srcStorage, srcObjActualPath)
 		if err != nil {
@@ -81,14 +87,19 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 				return nil, errors.WithMessagef(err, "failed get [%s] link", srcObjPath)
 			}
 			fs := stream.FileStream{
-				Obj: srcObj,
-				Ctx: ctx,
+				Obj:      srcObj,
+				Ctx:      ctx,
+				HashInfo: srcObj.GetHash(),
 			}
 			// any link provided is seekable
 			ss, err := stream.NewSeekableStream(fs, link)
 			if err != nil {
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
+			if err := stream.PrepareRapidUpload(dstStorage, ss); err != nil {
+				_ = ss.Close()
+				return nil, errors.WithMessagef(err, "failed hash [%s]", srcObjPath)
+			}
 			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
 		}
 	}
@@ -157,13 +168,20 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 		return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
 	}
 	fs := stream.FileStream{
-		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Obj:      srcFile,
+		Ctx:      tsk.Ctx(),
+		HashInfo: srcFile.GetHash(),
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
+	tsk.Status = "hashing"
+	if err := stream.PrepareRapidUpload(dstStorage, ss); err != nil {
+		_ = ss.Close()
+		return errors.WithMessagef(err, "failed hash [%s]", srcFilePath)
+	}
+	tsk.Status = "uploading"
 	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
 }
@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
@@ -16,6 +17,8 @@ import (
 type ListArgs struct {
 	Refresh bool
 	NoLog   bool
+	// IP is the client ip to keep on the same storage of a balance group, taken from the gin context if empty
+	IP string
 }
 
 func List(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
@@ -120,6 +123,33 @@ func GetStorage(path string, args *GetStoragesArgs) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+// getStorageAndActualPath is like op.GetStorageAndActualPath, but the storages the user in ctx can't see are not found
+func getStorageAndActualPath(ctx context.Context, path string) (driver.Driver, string, error) {
+	storages, actualPath, err := getStoragesAndActualPath(ctx, path, "")
+	if err != nil {
+		return nil, "", err
+	}
+	return storages[0], actualPath, nil
+}
+
+func getStoragesAndActualPath(ctx context.Context, path, key string) ([]driver.Driver, string, error) {
+	storages, actualPath, err := op.GetStoragesAndActualPath(path, key)
+	if err != nil {
+		return nil, "", err
+	}
+	user, _ := ctx.Value("user").(*model.User)
+	visible := make([]driver.Driver, 0, len(storages))
+	for _, storage := range storages {
+		if op.CanSeeStorage(user, storage.GetStorage()) {
+			visible = append(visible, storage)
+		}
+	}
+	if len(visible) == 0 {
+		return nil, "", errs.NewErr(errs.StorageNotFound, "rawPath: %s", path)
+	}
+	return visible, actualPath, nil
+}
+
 func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	res, err := other(ctx, args)
 	if err != nil {
@@ -15,14 +15,15 @@ func get(ctx context.Context, path string) (model.Obj, error) {
 	path = utils.FixAndCleanPath(path)
 	// maybe a virtual file
 	if path != "/" {
-		virtualFiles := op.GetStorageVirtualFilesByPath(stdpath.Dir(path))
+		user, _ := ctx.Value("user").(*model.User)
+		virtualFiles := op.GetVisibleStorageVirtualFilesByPath(stdpath.Dir(path), user, false)
 		for _, f := range virtualFiles {
 			if f.GetName() == stdpath.Base(path) {
 				return f, nil
 			}
 		}
 	}
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		// if there are no storage prefix with path, maybe root folder
 		if path == "/" {
@@ -9,14 +9,26 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storages, actualPath, err := getStoragesAndActualPath(ctx, path, args.IP)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed get storage")
 	}
-	l, obj, err := op.Link(ctx, storage, actualPath, args)
+	var l *model.Link
+	var obj model.Obj
+	// fall back to the other storages of the balance group
+	for i, storage := range storages {
+		l, obj, err = op.Link(ctx, storage, actualPath, args)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+		if i < len(storages)-1 {
+			log.Warnf("failed link %s with %s, try the next storage: %+v", path, storage.GetStorage().MountPath, err)
+		}
+	}
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed link")
 	}
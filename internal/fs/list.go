@@ -3,9 +3,11 @@ package fs
 import (
 	"context"
 
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -14,18 +16,31 @@ import (
 func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
-	virtualFiles := op.GetStorageVirtualFilesByPath(path)
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	virtualFiles := op.GetVisibleStorageVirtualFilesByPath(path, user, true)
+	ip := args.IP
+	if c, ok := ctx.(*gin.Context); ok && ip == "" {
+		ip = ipfilter.ClientIP(c.Request)
+	}
+	storages, actualPath, err := getStoragesAndActualPath(ctx, path, ip)
 	if err != nil && len(virtualFiles) == 0 {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
 
 	var _objs []model.Obj
-	if storage != nil {
-		_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
-			ReqPath: path,
-			Refresh: args.Refresh,
-		})
+	if len(storages) > 0 {
+		// fall back to the other storages of the balance group
+		for i, storage := range storages {
+			_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
+				ReqPath: path,
+				Refresh: args.Refresh,
+			})
+			if err == nil || ctx.Err() != nil {
+				break
+			}
+			if i < len(storages)-1 {
+				log.Warnf("failed list %s with %s, try the next storage: %+v", path, storage.GetStorage().MountPath, err)
+			}
+		}
 		if err != nil {
 			if !args.NoLog {
 				log.Errorf("fs/list: %+v", err)
@@ -10,7 +10,7 @@ import (
 )
 
 func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
@@ -18,22 +18,27 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 }
 
 func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
-	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	srcStorage, srcActualPath, err := getStorageAndActualPath(ctx, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get src storage")
 	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	dstStorage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
 	}
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
-		return errors.WithStack(errs.MoveBetweenTwoStorages)
+		// server-side move between the storages of the same account
+		err := op.CrossStorageMove(ctx, srcStorage, srcActualPath, dstStorage, dstDirActualPath, lazyCache...)
+		if errors.Is(err, errs.NotSupport) {
+			return errors.WithStack(errs.MoveBetweenTwoStorages)
+		}
+		return err
 	}
 	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
-	storage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	storage, srcActualPath, err := getStorageAndActualPath(ctx, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
@@ -41,7 +46,7 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 }
 
 func remove(ctx context.Context, path string) error {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
@@ -49,7 +54,7 @@ func remove(ctx context.Context, path string) error {
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(args.Path)
+	storage, actualPath, err := getStorageAndActualPath(ctx, args.Path)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
@@ -35,7 +35,7 @@ var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
 func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskInfoWithCreator, error) {
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	storage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
@@ -65,7 +65,7 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 
 // putDirect put the file and return after finish
 func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	storage, dstDirActualPath, err := getStorageAndActualPath(ctx, dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
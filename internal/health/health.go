@@ -0,0 +1,268 @@
+// Package health probes the enabled storages periodically, reinitializes the ones failing repeatedly
+// with backoff and alerts by a webhook when a storage stays down
+package health
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	probeTimeout = time.Minute
+	// the backoff of the reinitialization is doubled each time up to it
+	maxReinitBackoff = 6 * time.Hour
+)
+
+// events posted to the alert webhook
+const (
+	EventDown      = "storage_down"
+	EventRecovered = "storage_recovered"
+)
+
+// State is the current health of a storage
+type State struct {
+	StorageID  uint      `json:"storage_id"`
+	MountPath  string    `json:"mount_path"`
+	Status     string    `json:"status"`
+	Failures   int       `json:"failures"` // consecutive failed checks
+	Reinits    int       `json:"reinits"`  // failed reinitializations since the storage is down
+	NextReinit time.Time `json:"next_reinit"`
+	Down       bool      `json:"down"` // alerted as down
+	LastCheck  time.Time `json:"last_check"`
+	// the health tracked by the requests of the users
+	Requests op.StorageHealth `json:"requests"`
+}
+
+type state struct {
+	sync.Mutex
+	failures   int
+	reinits    int
+	nextReinit time.Time
+	down       bool
+	lastCheck  time.Time
+}
+
+var (
+	states    generic_sync.MapOf[uint, *state]
+	checkCron *cron.Cron
+	running   atomic.Bool
+	lastPrune time.Time
+	client    = &http.Client{Timeout: 30 * time.Second}
+)
+
+// probe checks the storage by the driver-specific check if implemented, otherwise lists the root
+func probe(ctx context.Context, storage driver.Driver) error {
+	if s := storage.GetStorage().Status; s != op.WORK {
+		return errors.New(s)
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	if c, ok := storage.(driver.HealthChecker); ok {
+		return c.HealthCheck(ctx)
+	}
+	_, err := op.List(ctx, storage, "/", model.ListArgs{Refresh: true})
+	return err
+}
+
+func getState(id uint) *state {
+	s, _ := states.LoadOrStore(id, &state{})
+	return s
+}
+
+// Check probes the storage, reinitializes it if it has failed enough times and the backoff is over,
+// then records the result to the history
+func Check(ctx context.Context, storage driver.Driver) error {
+	s := storage.GetStorage()
+	st := getState(s.ID)
+	st.Lock()
+	defer st.Unlock()
+	start := time.Now()
+	err := probe(ctx, storage)
+	check := model.StorageCheck{StorageID: s.ID}
+	if err != nil {
+		st.failures++
+		max := setting.GetInt(conf.StorageCheckMaxFailures, 3)
+		if st.failures >= max && !start.Before(st.nextReinit) {
+			check.Reinit = true
+			if err = op.ReinitStorage(ctx, s.ID); err == nil {
+				err = probe(ctx, storage)
+			}
+			if err != nil {
+				st.reinits++
+				backoff := min(interval()<<min(st.reinits, 16), maxReinitBackoff)
+				st.nextReinit = time.Now().Add(backoff)
+				log.Warnf("storage [%s] is down, reinit again after %s: %+v", s.MountPath, backoff, err)
+				if !st.down {
+					st.down = true
+					alert(EventDown, storage, err, st.failures)
+				}
+			}
+		}
+	}
+	st.lastCheck = time.Now()
+	check.Latency = time.Since(start).Milliseconds()
+	if err == nil {
+		check.Success = true
+		if st.down {
+			log.Infof("storage [%s] is recovered", s.MountPath)
+			alert(EventRecovered, storage, nil, st.failures)
+		}
+		st.failures, st.reinits, st.nextReinit, st.down = 0, 0, time.Time{}, false
+	} else {
+		check.Status = err.Error()
+	}
+	if err := db.CreateStorageCheck(&check); err != nil {
+		log.Errorf("failed record storage check: %+v", err)
+	}
+	return err
+}
+
+// CheckAll checks all the enabled storages concurrently, a run is skipped if the last one is not finished
+func CheckAll(ctx context.Context) {
+	if !running.CompareAndSwap(false, true) {
+		return
+	}
+	defer running.Store(false)
+	var wg sync.WaitGroup
+	for _, storage := range op.GetAllStorages() {
+		wg.Add(1)
+		go func(storage driver.Driver) {
+			defer wg.Done()
+			_ = Check(ctx, storage)
+		}(storage)
+	}
+	wg.Wait()
+	if days := setting.GetInt(conf.StorageCheckHistoryDays, 7); days > 0 && time.Since(lastPrune) > time.Hour {
+		lastPrune = time.Now()
+		if err := db.DeleteStorageChecksBefore(time.Now().AddDate(0, 0, -days)); err != nil {
+			log.Errorf("failed prune storage check history: %+v", err)
+		}
+	}
+}
+
+// States returns the current health of the enabled storages
+func States() []State {
+	storages := op.GetAllStorages()
+	res := make([]State, 0, len(storages))
+	for _, storage := range storages {
+		s := storage.GetStorage()
+		item := State{
+			StorageID: s.ID,
+			MountPath: s.MountPath,
+			Status:    s.Status,
+			Requests:  op.GetStorageHealth(s.MountPath),
+		}
+		if st, ok := states.Load(s.ID); ok {
+			st.Lock()
+			item.Failures, item.Reinits, item.NextReinit = st.failures, st.reinits, st.nextReinit
+			item.Down, item.LastCheck = st.down, st.lastCheck
+			st.Unlock()
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func History(storageId uint, pageIndex, pageSize int) ([]model.StorageCheck, int64, error) {
+	return db.GetStorageChecks(storageId, pageIndex, pageSize)
+}
+
+type alertBody struct {
+	Event     string    `json:"event"`
+	StorageID uint      `json:"storage_id"`
+	MountPath string    `json:"mount_path"`
+	Driver    string    `json:"driver"`
+	Error     string    `json:"error,omitempty"`
+	Failures  int       `json:"failures"`
+	Time      time.Time `json:"time"`
+}
+
+// alert posts the event to the webhook if configured
+func alert(event string, storage driver.Driver, err error, failures int) {
+	url := setting.GetStr(conf.StorageAlertWebhook)
+	if url == "" {
+		return
+	}
+	s := storage.GetStorage()
+	body := alertBody{
+		Event:     event,
+		StorageID: s.ID,
+		MountPath: s.MountPath,
+		Driver:    s.Driver,
+		Failures:  failures,
+		Time:      time.Now(),
+	}
+	if err != nil {
+		body.Error = err.Error()
+	}
+	go func() {
+		data, err := utils.Json.Marshal(body)
+		if err != nil {
+			return
+		}
+		res, err := client.Post(url, "application/json", bytes.NewReader(data))
+		if err != nil {
+			log.Errorf("failed post storage alert: %+v", err)
+			return
+		}
+		_ = res.Body.Close()
+		if res.StatusCode >= 400 {
+			log.Errorf("failed post storage alert: %s", res.Status)
+		}
+	}()
+}
+
+func interval() time.Duration {
+	return time.Minute * time.Duration(max(setting.GetInt(conf.StorageCheckInterval, 10), 1))
+}
+
+// Init schedules the periodic check and reschedules it when the interval is changed
+func Init() {
+	schedule(setting.GetInt(conf.StorageCheckInterval, 10))
+	op.RegisterSettingItemHook(conf.StorageCheckInterval, func(item *model.SettingItem) error {
+		interval, err := strconv.Atoi(item.Value)
+		if err != nil {
+			return errors.Wrap(err, "invalid storage check interval")
+		}
+		schedule(interval)
+		return nil
+	})
+	op.RegisterStorageHook(func(typ string, storage driver.Driver) {
+		if typ == "del" {
+			states.Delete(storage.GetStorage().ID)
+		}
+	})
+}
+
+// schedule runs CheckAll every interval minutes, 0 disables the check
+func schedule(interval int) {
+	if checkCron != nil {
+		// stopping waits for the running CheckAll, which should not block saving the setting
+		go checkCron.Stop()
+		checkCron = nil
+	}
+	if interval <= 0 {
+		return
+	}
+	checkCron = cron.NewCron(time.Minute * time.Duration(interval))
+	checkCron.Do(func() {
+		CheckAll(context.Background())
+	})
+}
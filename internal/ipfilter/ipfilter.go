@@ -0,0 +1,174 @@
+// Package ipfilter restricts the access by the ip of the client, with the global lists in the settings
+// and the lists of each user, and resolves the ip of the client behind trusted reverse proxies
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// List is a list of ips and cidrs
+type List []netip.Prefix
+
+// ParseList parses ips or cidrs separated by new lines or commas, lines starting with # are ignored
+func ParseList(s string) (List, error) {
+	var l List
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, item := range strings.Split(line, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if !strings.Contains(item, "/") {
+				addr, err := netip.ParseAddr(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ip: %s", item)
+				}
+				l = append(l, netip.PrefixFrom(addr, addr.BitLen()))
+				continue
+			}
+			p, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr: %s", item)
+			}
+			l = append(l, p.Masked())
+		}
+	}
+	return l, nil
+}
+
+// Contains reports whether the ip is in the list
+func (l List) Contains(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range l {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed reports whether the ip is not denied and is allowed if an allow list is given
+func Allowed(ip netip.Addr, allow, deny List) bool {
+	if deny.Contains(ip) {
+		return false
+	}
+	return len(allow) == 0 || allow.Contains(ip)
+}
+
+var (
+	globalAllow atomic.Pointer[List]
+	globalDeny  atomic.Pointer[List]
+	trusted     atomic.Pointer[List]
+)
+
+func load(p *atomic.Pointer[List]) List {
+	if l := p.Load(); l != nil {
+		return *l
+	}
+	return nil
+}
+
+// SetTrustedProxies sets the proxies whose forwarded headers are trusted
+func SetTrustedProxies(proxies []string) error {
+	l, err := ParseList(strings.Join(proxies, ","))
+	if err != nil {
+		return err
+	}
+	trusted.Store(&l)
+	return nil
+}
+
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	return addr.Unmap(), err == nil
+}
+
+// ClientIP returns the ip of the client, X-Forwarded-For and X-Real-Ip are only used
+// if the request comes from a trusted proxy or a unix socket, X-Forwarded-For is walked
+// from the right to the first address which is not a trusted proxy
+func ClientIP(r *http.Request) string {
+	addr, ok := remoteAddr(r)
+	proxies := load(&trusted)
+	if ok && !proxies.Contains(addr) {
+		return addr.String()
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			if i == 0 || !proxies.Contains(hop) {
+				return hop.Unmap().String()
+			}
+		}
+	}
+	if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-Ip"))); err == nil {
+		return ip.Unmap().String()
+	}
+	if ok {
+		return addr.String()
+	}
+	return ""
+}
+
+// Check returns errs.IPDenied if the ip is denied by the global lists, or by the lists of the user if given,
+// unknown ips, e.g. of unix sockets without forwarded headers, are only denied if an allow list applies
+func Check(ip string, user *model.User) error {
+	allow, deny := load(&globalAllow), load(&globalDeny)
+	var userAllow, userDeny List
+	if user != nil {
+		var err error
+		if userAllow, err = ParseList(user.AllowedIPs); err != nil {
+			return err
+		}
+		if userDeny, err = ParseList(user.DeniedIPs); err != nil {
+			return err
+		}
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		if len(allow) > 0 || len(userAllow) > 0 {
+			return errs.IPDenied
+		}
+		return nil
+	}
+	if !Allowed(addr, allow, deny) || !Allowed(addr, userAllow, userDeny) {
+		return errs.IPDenied
+	}
+	return nil
+}
+
+func settingHook(p *atomic.Pointer[List]) op.SettingItemHook {
+	return func(item *model.SettingItem) error {
+		l, err := ParseList(item.Value)
+		if err != nil {
+			return err
+		}
+		p.Store(&l)
+		return nil
+	}
+}
+
+func init() {
+	op.RegisterSettingItemHook(conf.IPAllowList, settingHook(&globalAllow))
+	op.RegisterSettingItemHook(conf.IPDenyList, settingHook(&globalDeny))
+}
@@ -0,0 +1,85 @@
+package ipfilter
+
+import (
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestParseList(t *testing.T) {
+	l, err := ParseList("# office\n10.0.0.0/8, 192.168.1.1\n\n2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(l))
+	}
+	for ip, want := range map[string]bool{
+		"10.1.2.3":        true,
+		"192.168.1.1":     true,
+		"192.168.1.2":     false,
+		"::ffff:10.0.0.1": true,
+		"2001:db8::1":     true,
+		"2001:db9::1":     false,
+	} {
+		if got := l.Contains(netip.MustParseAddr(ip)); got != want {
+			t.Errorf("contains %s: expected %v, got %v", ip, want, got)
+		}
+	}
+	if _, err := ParseList("10.0.0.0/33"); err == nil {
+		t.Error("expected error for invalid cidr")
+	}
+	if _, err := ParseList("example.com"); err == nil {
+		t.Error("expected error for invalid ip")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	user := &model.User{AllowedIPs: "10.0.0.0/8", DeniedIPs: "10.0.0.1"}
+	for ip, want := range map[string]bool{
+		"10.0.0.2": true,
+		"10.0.0.1": false,
+		"1.1.1.1":  false,
+		"":         false,
+	} {
+		if got := Check(ip, user) == nil; got != want {
+			t.Errorf("check %q: expected %v, got %v", ip, want, got)
+		}
+	}
+	if err := Check("", nil); err != nil {
+		t.Errorf("unknown ip should be allowed without allow list: %v", err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	if err := SetTrustedProxies([]string{"127.0.0.1", "10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	defer trusted.Store(nil)
+	cases := []struct {
+		remote, xff, real, want string
+	}{
+		{"1.2.3.4:1234", "5.6.7.8", "", "1.2.3.4"},
+		{"127.0.0.1:1234", "5.6.7.8", "", "5.6.7.8"},
+		{"127.0.0.1:1234", "9.9.9.9, 5.6.7.8, 10.0.0.2", "", "5.6.7.8"},
+		{"127.0.0.1:1234", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"127.0.0.1:1234", "", "5.6.7.8", "5.6.7.8"},
+		{"127.0.0.1:1234", "", "", "127.0.0.1"},
+		{"@", "5.6.7.8", "", "5.6.7.8"},
+		{"@", "", "", ""},
+	}
+	for _, c := range cases {
+		r := &http.Request{RemoteAddr: c.remote, Header: http.Header{}}
+		if c.xff != "" {
+			r.Header.Set("X-Forwarded-For", c.xff)
+		}
+		if c.real != "" {
+			r.Header.Set("X-Real-Ip", c.real)
+		}
+		if got := ClientIP(r); got != c.want {
+			t.Errorf("%+v: expected %s, got %s", c, c.want, got)
+		}
+	}
+}
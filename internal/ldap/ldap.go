@@ -0,0 +1,278 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	goldap "gopkg.in/ldap.v3"
+)
+
+var (
+	ErrUserNotFound = errors.New("user does not exist or too many entries returned")
+	ErrAuthFailed   = errors.New("failed to auth")
+
+	syncCron *cron.Cron
+	syncMu   sync.Mutex
+)
+
+func dial(ldapServer string) (*goldap.Conn, error) {
+	var tlsEnabled bool = false
+	if strings.HasPrefix(ldapServer, "ldaps://") {
+		tlsEnabled = true
+		ldapServer = strings.TrimPrefix(ldapServer, "ldaps://")
+	} else if strings.HasPrefix(ldapServer, "ldap://") {
+		ldapServer = strings.TrimPrefix(ldapServer, "ldap://")
+	}
+
+	if tlsEnabled {
+		return goldap.DialTLS("tcp", ldapServer, &tls.Config{InsecureSkipVerify: true})
+	} else {
+		return goldap.Dial("tcp", ldapServer)
+	}
+}
+
+// connect dials the server and binds with the read only manager user if configured
+func connect() (*goldap.Conn, error) {
+	l, err := dial(setting.GetStr(conf.LdapServer))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to LDAP")
+	}
+	managerDN := setting.GetStr(conf.LdapManagerDN)
+	managerPassword := setting.GetStr(conf.LdapManagerPassword)
+	if managerDN != "" && managerPassword != "" {
+		if err := l.Bind(managerDN, managerPassword); err != nil {
+			l.Close()
+			return nil, errors.Wrap(err, "failed to bind to LDAP")
+		}
+	}
+	return l, nil
+}
+
+// searchUser finds the entry of the username with the user search filter, e.g. (uid=%s)
+func searchUser(l *goldap.Conn, username string) (*goldap.Entry, error) {
+	searchRequest := goldap.NewSearchRequest(
+		setting.GetStr(conf.LdapUserSearchBase),
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(setting.GetStr(conf.LdapUserSearchFilter), goldap.EscapeFilter(username)),
+		[]string{"dn", "memberOf"},
+		nil,
+	)
+	sr, err := l.Search(searchRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "LDAP search failed")
+	}
+	if len(sr.Entries) != 1 {
+		return nil, ErrUserNotFound
+	}
+	return sr.Entries[0], nil
+}
+
+// userGroups returns the dn of the groups of the user, searched with the group search filter
+// whose %s is replaced by the dn of the user, e.g. (member=%s), or read from memberOf if the filter is empty
+func userGroups(l *goldap.Conn, entry *goldap.Entry) ([]string, error) {
+	filter := setting.GetStr(conf.LdapGroupSearchFilter)
+	if filter == "" {
+		return entry.GetAttributeValues("memberOf"), nil
+	}
+	base := setting.GetStr(conf.LdapGroupSearchBase)
+	if base == "" {
+		base = setting.GetStr(conf.LdapUserSearchBase)
+	}
+	sr, err := l.Search(goldap.NewSearchRequest(
+		base,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		strings.ReplaceAll(filter, "%s", goldap.EscapeFilter(entry.DN)),
+		[]string{"dn"},
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "LDAP group search failed")
+	}
+	groups := make([]string, 0, len(sr.Entries))
+	for _, e := range sr.Entries {
+		groups = append(groups, e.DN)
+	}
+	return groups, nil
+}
+
+// syncUser applies the group mapping to the user managed by ldap
+func syncUser(l *goldap.Conn, entry *goldap.Entry, user *model.User) (bool, error) {
+	rules, err := parseRules(setting.GetStr(conf.LdapGroupMapping))
+	if err != nil {
+		return false, errors.Wrap(err, "invalid ldap group mapping")
+	}
+	changed := user.LdapDN != entry.DN
+	user.LdapDN = entry.DN
+	if len(rules) == 0 {
+		return changed, nil
+	}
+	groups, err := userGroups(l, entry)
+	if err != nil {
+		return false, err
+	}
+	defPermission := int32(setting.GetInt(conf.LdapDefaultPermission, 0))
+	return Apply(rules, groups, defPermission, setting.GetStr(conf.LdapDefaultDir), user) || changed, nil
+}
+
+// Login verifies the password against the directory, then creates the user or refreshes its permissions
+func Login(username, password string) (*model.User, error) {
+	l, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	entry, err := searchUser(l, username)
+	if err != nil {
+		return nil, err
+	}
+	// Bind as the user to verify their password
+	if err := l.Bind(entry.DN, password); err != nil {
+		return nil, errors.Wrap(ErrAuthFailed, err.Error())
+	}
+	utils.Log.Infof("Auth successful username:%s", username)
+	// bind back to the manager to search the groups
+	if err := rebind(l); err != nil {
+		return nil, err
+	}
+	user, err := op.GetUserByName(username)
+	if err != nil {
+		return register(l, entry, username)
+	}
+	// the built-in admin and guest are never managed by ldap
+	if user.LdapDN == "" && (user.IsAdmin() || user.IsGuest()) {
+		return user, nil
+	}
+	u := *user
+	changed, err := syncUser(l, entry, &u)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := op.UpdateUser(&u); err != nil {
+			return nil, err
+		}
+	}
+	return &u, nil
+}
+
+func rebind(l *goldap.Conn) error {
+	managerDN := setting.GetStr(conf.LdapManagerDN)
+	managerPassword := setting.GetStr(conf.LdapManagerPassword)
+	if managerDN == "" || managerPassword == "" {
+		return nil
+	}
+	return errors.Wrap(l.Bind(managerDN, managerPassword), "failed to bind to LDAP")
+}
+
+func register(l *goldap.Conn, entry *goldap.Entry, username string) (*model.User, error) {
+	if username == "" {
+		return nil, errors.New("cannot get username from ldap provider")
+	}
+	user := &model.User{
+		ID:         0,
+		Username:   username,
+		Password:   random.String(16),
+		Permission: int32(setting.GetInt(conf.LdapDefaultPermission, 0)),
+		BasePath:   setting.GetStr(conf.LdapDefaultDir),
+		Role:       0,
+		Disabled:   false,
+	}
+	if _, err := syncUser(l, entry, user); err != nil {
+		return nil, err
+	}
+	if err := db.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Sync refreshes the permissions of all the users managed by ldap, users removed from the directory are disabled
+func Sync() error {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	users, err := db.GetLdapUsers()
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	l, err := connect()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	for i := range users {
+		user := &users[i]
+		entry, err := searchUser(l, user.Username)
+		if errors.Is(err, ErrUserNotFound) {
+			if !user.Disabled {
+				utils.Log.Infof("disable ldap user [%s] removed from the directory", user.Username)
+				user.Disabled = true
+				if err := op.UpdateUser(user); err != nil {
+					utils.Log.Errorf("failed disable ldap user [%s]: %+v", user.Username, err)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		changed, err := syncUser(l, entry, user)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := op.UpdateUser(user); err != nil {
+				utils.Log.Errorf("failed update ldap user [%s]: %+v", user.Username, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Init schedules the periodic sync and reschedules it when the interval is changed
+func Init() {
+	schedule(setting.GetInt(conf.LdapSyncInterval, 0))
+	op.RegisterSettingItemHook(conf.LdapSyncInterval, func(item *model.SettingItem) error {
+		interval, err := strconv.Atoi(item.Value)
+		if err != nil {
+			return errors.Wrap(err, "invalid ldap sync interval")
+		}
+		schedule(interval)
+		return nil
+	})
+}
+
+// schedule runs Sync every interval minutes, 0 disables the sync
+func schedule(interval int) {
+	if syncCron != nil {
+		syncCron.Stop()
+		syncCron = nil
+	}
+	if interval <= 0 {
+		return
+	}
+	syncCron = cron.NewCron(time.Minute * time.Duration(interval))
+	syncCron.Do(func() {
+		if !setting.GetBool(conf.LdapLoginEnabled) {
+			return
+		}
+		if err := Sync(); err != nil {
+			utils.Log.Errorf("failed sync ldap users: %+v", err)
+		}
+	})
+}
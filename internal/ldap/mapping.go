@@ -0,0 +1,39 @@
+package ldap
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	goldap "gopkg.in/ldap.v3"
+)
+
+func parseRules(s string) ([]model.GroupRule, error) {
+	var rules []model.GroupRule
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	err := utils.Json.UnmarshalFromString(s, &rules)
+	return rules, err
+}
+
+// groupNames returns the dn and the cn of each group
+func groupNames(dns []string) []string {
+	names := make([]string, 0, len(dns)*2)
+	for _, dn := range dns {
+		names = append(names, strings.ToLower(dn))
+		if parsed, err := goldap.ParseDN(dn); err == nil && len(parsed.RDNs) > 0 {
+			for _, attr := range parsed.RDNs[0].Attributes {
+				if strings.EqualFold(attr.Type, "cn") {
+					names = append(names, strings.ToLower(attr.Value))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Apply applies the rules to the user, rules match either the dn or the cn of the groups
+func Apply(rules []model.GroupRule, groups []string, defPermission int32, defBasePath string, user *model.User) bool {
+	return model.ApplyGroupRules(rules, groupNames(groups), defPermission, defBasePath, user)
+}
@@ -0,0 +1,40 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestApply(t *testing.T) {
+	rules := []model.GroupRule{
+		{Group: "cn=Admins,ou=groups,dc=example,dc=com", Admin: true},
+		{Group: "editors", Permission: 0b1111000, BasePath: "/team"},
+		{Group: "viewers", Permission: 0b1, BasePath: "/public"},
+	}
+	tests := []struct {
+		groups     []string
+		permission int32
+		basePath   string
+		role       int
+	}{
+		{nil, 2, "/default", model.GENERAL},
+		{[]string{"cn=editors,ou=groups,dc=example,dc=com"}, 0b1111000, "/team", model.GENERAL},
+		{[]string{"CN=Viewers,ou=groups,dc=example,dc=com", "cn=editors,ou=groups,dc=example,dc=com"}, 0b1111001, "/team", model.GENERAL},
+		{[]string{"cn=admins,ou=groups,dc=example,dc=com"}, model.AllPermissions, "/default", model.GENERAL},
+	}
+	for _, tt := range tests {
+		user := &model.User{}
+		Apply(rules, tt.groups, 2, "/default", user)
+		if user.Permission != tt.permission || user.BasePath != tt.basePath || user.Role != tt.role {
+			t.Errorf("groups %v: got permission %b, base path %s, role %d", tt.groups, user.Permission, user.BasePath, user.Role)
+		}
+		if Apply(rules, tt.groups, 2, "/default", user) {
+			t.Errorf("groups %v: expect no change when applied again", tt.groups)
+		}
+	}
+	admin := &model.User{Role: model.ADMIN}
+	if Apply(rules, nil, 2, "/default", admin) || !admin.IsAdmin() {
+		t.Errorf("the admin is changed: %+v", admin)
+	}
+}
@@ -0,0 +1,182 @@
+// Package login guards the authentication of web, ldap, webdav and s3 against brute-force,
+// failures are counted per ip and per account and persisted, so that locks survive restarts
+package login
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var lockCache = cache.NewMemCache(cache.WithShards[*model.LoginLock](16))
+
+// seenCache throttles the history of protocols authenticating every request, such as webdav
+var seenCache = cache.NewMemCache(cache.WithShards[bool](16))
+
+var lastPrune atomic.Int64
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func keys(username, ip string) []string {
+	var res []string
+	if ip != "" {
+		res = append(res, ipKey(ip))
+	}
+	if username != "" {
+		res = append(res, userKey(username))
+	}
+	return res
+}
+
+func lockDuration() time.Duration {
+	return time.Duration(setting.GetInt(conf.LoginLockDuration, 5)) * time.Minute
+}
+
+func getLock(key string) *model.LoginLock {
+	if l, ok := lockCache.Get(key); ok {
+		return l
+	}
+	l, err := db.GetLoginLock(key)
+	if err != nil {
+		l = &model.LoginLock{Key: key}
+	}
+	lockCache.Set(key, l, cache.WithEx[*model.LoginLock](10*time.Minute))
+	return l
+}
+
+// failures returns the failures not yet forgotten
+func failures(l *model.LoginLock) int {
+	if l.Locked() || time.Since(time.Unix(l.LastFailure, 0)) < lockDuration() {
+		return l.Failures
+	}
+	return 0
+}
+
+// Check returns errs.LoginLocked if the ip or the account is locked
+func Check(username, ip string) error {
+	for _, key := range keys(username, ip) {
+		if l := getLock(key); l.Locked() {
+			return errors.WithMessagef(errs.LoginLocked, "locked until %s", time.Unix(l.LockedUntil, 0).Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// NeedCaptcha reports whether the ip or the account has failed enough times to be challenged
+func NeedCaptcha(username, ip string) bool {
+	after := setting.GetInt(conf.LoginCaptchaAfter, 0)
+	if after <= 0 {
+		return false
+	}
+	for _, key := range keys(username, ip) {
+		if failures(getLock(key)) >= after {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed records the failed attempt and locks the ip and the account if they fail too many times
+func Failed(a *model.LoginAttempt) {
+	a.Success = false
+	max := setting.GetInt(conf.LoginMaxFailures, 5)
+	now := time.Now()
+	for _, key := range keys(a.Username, a.IP) {
+		l := *getLock(key)
+		if l.Locked() {
+			// attempts during the lock do not extend it
+			continue
+		}
+		l.Failures = failures(&l) + 1
+		l.LastFailure = now.Unix()
+		if max > 0 && l.Failures >= max {
+			l.LockedUntil = now.Add(lockDuration()).Unix()
+			l.Failures = 0
+			log.Warnf("login locked: %s", key)
+		}
+		if err := db.SaveLoginLock(&l); err != nil {
+			log.Errorf("failed save login lock: %+v", err)
+			continue
+		}
+		lockCache.Set(key, &l, cache.WithEx[*model.LoginLock](10*time.Minute))
+	}
+	record(a)
+}
+
+// HasFailures reports whether the ip or the account has failures to be forgotten by Succeeded
+func HasFailures(username, ip string) bool {
+	for _, key := range keys(username, ip) {
+		if l := getLock(key); l.Failures > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Succeeded forgets the failures of the ip and the account and records the attempt,
+// attempts of webdav and s3 are recorded once a while as they authenticate every request
+func Succeeded(a *model.LoginAttempt) {
+	a.Success = true
+	for _, key := range keys(a.Username, a.IP) {
+		if l := getLock(key); l.Failures > 0 && !l.Locked() {
+			_ = Unlock(key)
+		}
+	}
+	if a.Method == model.LoginMethodWebDAV || a.Method == model.LoginMethodS3 {
+		seen := a.Method + "|" + a.Username + "|" + a.IP
+		if _, ok := seenCache.Get(seen); ok {
+			return
+		}
+		seenCache.Set(seen, true, cache.WithEx[bool](time.Hour))
+	}
+	record(a)
+}
+
+func record(a *model.LoginAttempt) {
+	if err := db.CreateLoginAttempt(a); err != nil {
+		log.Errorf("failed record login attempt: %+v", err)
+	}
+	days := setting.GetInt(conf.LoginHistoryDays, 30)
+	if last := lastPrune.Load(); days > 0 && time.Since(time.Unix(last, 0)) > time.Hour &&
+		lastPrune.CompareAndSwap(last, time.Now().Unix()) {
+		go func() {
+			if err := db.DeleteLoginAttemptsBefore(time.Now().AddDate(0, 0, -days)); err != nil {
+				log.Errorf("failed prune login history: %+v", err)
+			}
+		}()
+	}
+}
+
+// Unlock forgets the failures of the key, which is ip:<ip> or user:<username>
+func Unlock(key string) error {
+	lockCache.Del(key)
+	return db.DeleteLoginLock(key)
+}
+
+// UnlockUser forgets the failures of the account
+func UnlockUser(username string) error {
+	return Unlock(userKey(username))
+}
+
+func Locks() ([]model.LoginLock, error) {
+	return db.GetLoginLocks(time.Now().Unix())
+}
+
+func History(username, ip string, success *bool, pageIndex, pageSize int) ([]model.LoginAttempt, int64, error) {
+	return db.GetLoginAttempts(username, ip, success, pageIndex, pageSize)
+}
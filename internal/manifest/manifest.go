@@ -0,0 +1,395 @@
+// Package manifest applies a declarative manifest of storages, the storages are matched by mount path,
+// so applying the same manifest again changes nothing
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes the storages, the fields are the ones of model.Storage in json,
+// except that the addition is an object and an entry may inherit the fields of a template, e.g.
+//
+//	templates:
+//	  onedrive:
+//	    driver: Onedrive
+//	    addition:
+//	      region: global
+//	storages:
+//	  - mount_path: /od1
+//	    template: onedrive
+//	    addition:
+//	      refresh_token: xxx
+type Manifest struct {
+	Templates map[string]map[string]any `json:"templates" yaml:"templates"`
+	Storages  []map[string]any          `json:"storages" yaml:"storages"`
+}
+
+// actions of a change
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+	None   = "none"
+)
+
+type Diff struct {
+	Field string `json:"field"` // e.g. order or addition.refresh_token
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type Change struct {
+	MountPath string `json:"mount_path"`
+	Action    string `json:"action"`
+	Diffs     []Diff `json:"diffs,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	storage model.Storage // the desired storage
+	old     *model.Storage
+}
+
+// the fields not managed by the manifest
+var ignoredFields = map[string]struct{}{"id": {}, "status": {}, "modified": {}}
+
+// Parse parses a manifest in yaml or json
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(err, "invalid manifest")
+	}
+	return &m, nil
+}
+
+// resolve merges the entries onto their templates
+func (m *Manifest) resolve() ([]map[string]any, error) {
+	res := make([]map[string]any, 0, len(m.Storages))
+	seen := make(map[string]struct{}, len(m.Storages))
+	for i, entry := range m.Storages {
+		mountPath, _ := entry["mount_path"].(string)
+		if mountPath == "" {
+			return nil, errors.Errorf("storage #%d has no mount_path", i+1)
+		}
+		mountPath = utils.FixAndCleanPath(mountPath)
+		if _, ok := seen[mountPath]; ok {
+			return nil, errors.Errorf("duplicate mount_path [%s]", mountPath)
+		}
+		seen[mountPath] = struct{}{}
+		resolved := map[string]any{}
+		if name, ok := entry["template"]; ok {
+			template, ok := m.Templates[fmt.Sprint(name)]
+			if !ok {
+				return nil, errors.Errorf("template [%v] of [%s] not found", name, mountPath)
+			}
+			if _, ok := template["template"]; ok {
+				return nil, errors.Errorf("template [%v] can't inherit another template", name)
+			}
+			resolved = merge(resolved, template)
+		}
+		resolved = merge(resolved, entry)
+		delete(resolved, "template")
+		resolved["mount_path"] = mountPath
+		if err := validate(resolved); err != nil {
+			return nil, errors.WithMessagef(err, "invalid storage [%s]", mountPath)
+		}
+		res = append(res, resolved)
+	}
+	return res, nil
+}
+
+// validate rejects the unknown fields, which would be dropped when saved and then changed on every apply
+func validate(entry map[string]any) error {
+	driverName, _ := entry["driver"].(string)
+	info, ok := op.GetDriverInfoMap()[driverName]
+	if !ok {
+		return errors.Errorf("no driver named: %s", driverName)
+	}
+	fields, err := toMap(model.Storage{})
+	if err != nil {
+		return err
+	}
+	for k := range entry {
+		_, ignored := ignoredFields[k]
+		if _, ok := fields[k]; !ok || ignored {
+			return errors.Errorf("unknown field [%s]", k)
+		}
+	}
+	if _, ok := entry["addition"]; !ok {
+		return nil
+	}
+	addition, ok := entry["addition"].(map[string]any)
+	if !ok {
+		return errors.New("addition should be an object")
+	}
+	items := make(map[string]struct{}, len(info.Additional))
+	for _, item := range info.Additional {
+		items[item.Name] = struct{}{}
+	}
+	for k := range addition {
+		if _, ok := items[k]; !ok {
+			return errors.Errorf("unknown addition field [%s] of driver %s", k, driverName)
+		}
+	}
+	return nil
+}
+
+// merge returns a copy of dst with the fields of src set, the nested objects are merged too
+func merge(dst, src map[string]any) map[string]any {
+	res := make(map[string]any, len(dst)+len(src))
+	for k, v := range dst {
+		res[k] = v
+	}
+	for k, v := range src {
+		if sub, ok := v.(map[string]any); ok {
+			if old, ok := res[k].(map[string]any); ok {
+				res[k] = merge(old, sub)
+				continue
+			}
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// defaults returns the default fields of a new storage of the driver, the same as the ones filled by the frontend
+func defaults(driverName string) map[string]any {
+	info := op.GetDriverInfoMap()[driverName]
+	res := map[string]any{}
+	for _, item := range info.Common {
+		if v, ok := itemDefault(item.Type, item.Default); ok {
+			res[item.Name] = v
+		}
+	}
+	addition := map[string]any{}
+	for _, item := range info.Additional {
+		if v, ok := itemDefault(item.Type, item.Default); ok {
+			addition[item.Name] = v
+		}
+	}
+	res["addition"] = addition
+	return res
+}
+
+func itemDefault(typ, value string) (any, bool) {
+	switch typ {
+	// the type of an addition item is the go type if not specified
+	case conf.TypeNumber, "int", "int32", "int64", "uint", "float64":
+		v, err := strconv.ParseFloat(value, 64)
+		return v, err == nil
+	case conf.TypeBool:
+		v, err := strconv.ParseBool(value)
+		return v, err == nil
+	default:
+		return value, true
+	}
+}
+
+// toMap converts the storage to the fields of the manifest
+func toMap(storage model.Storage) (map[string]any, error) {
+	data, err := utils.Json.Marshal(storage)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]any
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	addition := map[string]any{}
+	if storage.Addition != "" {
+		if err := json.Unmarshal([]byte(storage.Addition), &addition); err != nil {
+			return nil, errors.Wrap(err, "invalid addition")
+		}
+	}
+	res["addition"] = addition
+	return res, nil
+}
+
+func toStorage(fields map[string]any) (model.Storage, error) {
+	var storage model.Storage
+	fields = merge(fields, nil)
+	addition, err := json.Marshal(fields["addition"])
+	if err != nil {
+		return storage, err
+	}
+	fields["addition"] = string(addition)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return storage, err
+	}
+	err = json.Unmarshal(data, &storage)
+	return storage, err
+}
+
+// diff compares the fields, values are compared by their json
+func diff(old, new map[string]any, ignored map[string]struct{}) []Diff {
+	var res []Diff
+	keys := make(map[string]struct{})
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range new {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		if _, ok := ignored[k]; ok {
+			continue
+		}
+		if k == "addition" && ignored != nil {
+			oldAddition, _ := old[k].(map[string]any)
+			newAddition, _ := new[k].(map[string]any)
+			for _, d := range diff(oldAddition, newAddition, nil) {
+				d.Field = "addition." + d.Field
+				res = append(res, d)
+			}
+			continue
+		}
+		o, _ := json.Marshal(old[k])
+		n, _ := json.Marshal(new[k])
+		if string(o) != string(n) {
+			res = append(res, Diff{Field: k, Old: old[k], New: new[k]})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Field < res[j].Field
+	})
+	return res
+}
+
+// Plan compares the manifest with the storages in the database, the storages not in the manifest are deleted if prune
+func Plan(m *Manifest, prune bool) ([]Change, error) {
+	entries, err := m.resolve()
+	if err != nil {
+		return nil, err
+	}
+	var res []Change
+	managed := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		mountPath := entry["mount_path"].(string)
+		managed[mountPath] = struct{}{}
+		change := Change{MountPath: mountPath}
+		var base map[string]any
+		if old, err := db.GetStorageByMountPath(mountPath); err == nil {
+			if old.Driver != entry["driver"] {
+				return nil, errors.Errorf("driver of [%s] cannot be changed", mountPath)
+			}
+			change.old = old
+			base, err = toMap(*old)
+		} else {
+			base = defaults(entry["driver"].(string))
+		}
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed resolve [%s]", mountPath)
+		}
+		desired := merge(base, entry)
+		if change.storage, err = toStorage(desired); err != nil {
+			return nil, errors.Wrapf(err, "invalid storage [%s]", mountPath)
+		}
+		switch {
+		case change.old == nil:
+			change.Action = Create
+			change.Diffs = diff(map[string]any{}, desired, ignoredFields)
+		default:
+			change.Diffs = diff(base, desired, ignoredFields)
+			change.storage.ID = change.old.ID
+			change.Action = None
+			if len(change.Diffs) > 0 {
+				change.Action = Update
+			}
+		}
+		res = append(res, change)
+	}
+	if !prune {
+		return res, nil
+	}
+	storages, err := db.GetAllStorages()
+	if err != nil {
+		return nil, err
+	}
+	for i := range storages {
+		s := storages[i]
+		if _, ok := managed[s.MountPath]; !ok {
+			res = append(res, Change{MountPath: s.MountPath, Action: Delete, old: &s})
+		}
+	}
+	return res, nil
+}
+
+// Apply applies the manifest and returns the changes, a failed change doesn't stop the others.
+// the storages are reloaded if reload is true, which should be false if the storages are not loaded,
+// e.g. in the cli, then the changes take effect after restart
+func Apply(ctx context.Context, m *Manifest, prune, reload bool) ([]Change, error) {
+	changes, err := Plan(m, prune)
+	if err != nil {
+		return nil, err
+	}
+	var failed int
+	for i := range changes {
+		if err := apply(ctx, &changes[i], reload); err != nil {
+			changes[i].Error = err.Error()
+			failed++
+		}
+	}
+	if failed > 0 {
+		return changes, errors.Errorf("failed apply %d of %d changes", failed, len(changes))
+	}
+	return changes, nil
+}
+
+func apply(ctx context.Context, change *Change, reload bool) error {
+	storage := change.storage
+	switch change.Action {
+	case Create:
+		if reload {
+			_, err := op.CreateStorage(ctx, storage)
+			return err
+		}
+		storage.Modified = time.Now()
+		return db.CreateStorage(&storage)
+	case Update:
+		if !reload {
+			storage.Modified = time.Now()
+			return db.UpdateStorage(&storage)
+		}
+		return update(ctx, change, storage)
+	case Delete:
+		if reload {
+			return op.DeleteStorageById(ctx, change.old.ID)
+		}
+		return db.DeleteStorageById(change.old.ID)
+	}
+	return nil
+}
+
+// update updates the loaded storage, it is enabled or disabled if changed
+func update(ctx context.Context, change *Change, storage model.Storage) error {
+	old := change.old
+	disabled := storage.Disabled
+	storage.Disabled = old.Disabled
+	for _, d := range change.Diffs {
+		if d.Field != "disabled" {
+			if err := op.UpdateStorage(ctx, storage); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	switch {
+	case disabled && !old.Disabled:
+		return op.DisableStorage(ctx, old.ID)
+	case !disabled && old.Disabled:
+		return op.EnableStorage(ctx, old.ID)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package manifest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/manifest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func parse(t *testing.T, text string) *manifest.Manifest {
+	t.Helper()
+	m, err := manifest.Parse([]byte(text))
+	if err != nil {
+		t.Fatalf("failed parse: %+v", err)
+	}
+	return m
+}
+
+func actions(changes []manifest.Change) map[string]string {
+	res := make(map[string]string, len(changes))
+	for _, c := range changes {
+		res[c.MountPath] = c.Action
+	}
+	return res
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	a, b := t.TempDir(), t.TempDir()
+	text := `
+templates:
+  local:
+    driver: Local
+    cache_expiration: 5
+    addition:
+      show_hidden: false
+storages:
+  - mount_path: /manifest_a
+    template: local
+    addition:
+      root_folder_path: %q
+  - mount_path: /manifest_b/
+    template: local
+    order: %d
+    addition:
+      root_folder_path: %q
+`
+	changes, err := manifest.Apply(ctx, parse(t, fmt.Sprintf(text, a, 1, b)), false, true)
+	if err != nil {
+		t.Fatalf("failed apply: %+v %+v", err, changes)
+	}
+	if got := actions(changes); got["/manifest_a"] != manifest.Create || got["/manifest_b"] != manifest.Create {
+		t.Fatalf("expected creating both, got %v", got)
+	}
+	storage, err := db.GetStorageByMountPath("/manifest_b")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	if storage.CacheExpiration != 5 || storage.Order != 1 || storage.WebdavPolicy == "" {
+		t.Errorf("expected the fields of the template, the entry and the defaults, got %+v", storage)
+	}
+
+	// applied again without change
+	changes, err = manifest.Plan(parse(t, fmt.Sprintf(text, a, 1, b)), false)
+	if err != nil {
+		t.Fatalf("failed plan: %+v", err)
+	}
+	for _, c := range changes {
+		if c.Action != manifest.None {
+			t.Errorf("expected no change of %s, got %s %+v", c.MountPath, c.Action, c.Diffs)
+		}
+	}
+
+	changes, err = manifest.Plan(parse(t, fmt.Sprintf(text, a, 2, b)), false)
+	if err != nil {
+		t.Fatalf("failed plan: %+v", err)
+	}
+	if c := changes[1]; c.Action != manifest.Update || len(c.Diffs) != 1 || c.Diffs[0].Field != "order" {
+		t.Errorf("expected updating the order only, got %s %+v", c.Action, c.Diffs)
+	}
+
+	// the storages not in the manifest are deleted by prune
+	changes, err = manifest.Apply(ctx, parse(t, fmt.Sprintf(`
+storages:
+  - mount_path: /manifest_b
+    driver: Local
+    order: 2
+    addition:
+      root_folder_path: %q
+`, b)), true, true)
+	if err != nil {
+		t.Fatalf("failed apply: %+v %+v", err, changes)
+	}
+	if got := actions(changes); got["/manifest_a"] != manifest.Delete || got["/manifest_b"] != manifest.Update {
+		t.Errorf("expected deleting a and updating b, got %v", got)
+	}
+	if _, err := db.GetStorageByMountPath("/manifest_a"); err == nil {
+		t.Errorf("expected a to be deleted")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, text := range []string{
+		"storages:\n  - driver: Local",
+		"storages:\n  - mount_path: /x\n    driver: None",
+		"storages:\n  - mount_path: /x\n    driver: Local\n    oder: 1",
+		"storages:\n  - mount_path: /x\n    driver: Local\n    addition:\n      root_path: /",
+		"storages:\n  - mount_path: /x\n    template: none",
+		"storages:\n  - mount_path: /x\n    driver: Local\n  - mount_path: /x/\n    driver: Local",
+	} {
+		if _, err := manifest.Plan(parse(t, text), false); err == nil {
+			t.Errorf("expected error of %q", text)
+		}
+	}
+}
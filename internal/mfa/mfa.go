@@ -0,0 +1,172 @@
+// Package mfa enforces the 2FA policy and manages the recovery codes
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp/totp"
+	log "github.com/sirupsen/logrus"
+)
+
+// policies of the mfa_required setting
+const (
+	PolicyNone  = "none"
+	PolicyAdmin = "admin"
+	PolicyWrite = "write"
+	PolicyAll   = "all"
+)
+
+// RecoveryCodeCount is the number of recovery codes generated at once
+const RecoveryCodeCount = 10
+
+// Enrolled reports whether the user has enrolled 2FA or webauthn
+func Enrolled(u *model.User) bool {
+	return u.OtpSecret != "" || len(u.WebAuthnCredentials()) > 0
+}
+
+func canWrite(u *model.User) bool {
+	return u.IsAdmin() || u.CanWrite() || u.CanRename() || u.CanMove() || u.CanCopy() || u.CanRemove() || u.CanWebdavManage()
+}
+
+// Required reports whether the policy requires the user to enroll
+func Required(u *model.User) bool {
+	if u.IsGuest() {
+		return false
+	}
+	switch setting.GetStr(conf.MfaRequired) {
+	case PolicyAdmin:
+		return u.IsAdmin()
+	case PolicyWrite:
+		return canWrite(u)
+	case PolicyAll:
+		return true
+	}
+	return false
+}
+
+// StartGracePeriod starts the grace period of the user required to enroll, called when the user logs in
+func StartGracePeriod(u *model.User) {
+	if u.MfaEnrollBy != 0 || !Required(u) || Enrolled(u) {
+		return
+	}
+	grace := time.Duration(setting.GetInt(conf.MfaGracePeriod, 7)) * 24 * time.Hour
+	deadline := time.Now().Add(grace).Unix()
+	if err := op.SetMfaEnrollBy(u, deadline); err != nil {
+		log.Errorf("failed set mfa enrolment deadline of %s: %+v", u.Username, err)
+		return
+	}
+	u.MfaEnrollBy = deadline
+}
+
+// Check returns errs.MfaRequired if the user is required to enroll but the grace period is over
+func Check(u *model.User) error {
+	if !Required(u) || Enrolled(u) {
+		return nil
+	}
+	if u.MfaEnrollBy != 0 && time.Now().Unix() > u.MfaEnrollBy {
+		return errs.MfaRequired
+	}
+	return nil
+}
+
+// VerifyLogin checks the second factor of a login with the password, a recovery code is accepted in place of the code.
+// the users enrolled with webauthn only have to login with webauthn or a recovery code
+func VerifyLogin(u *model.User, code string) error {
+	if !Enrolled(u) {
+		return nil
+	}
+	if u.OtpSecret != "" && totp.Validate(code, u.OtpSecret) {
+		return nil
+	}
+	if code != "" && UseRecoveryCode(u, code) == nil {
+		return nil
+	}
+	if u.OtpSecret == "" {
+		return errs.WebAuthnRequired
+	}
+	return errs.OtpCodeInvalid
+}
+
+func newCode() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:8] + "-" + code[8:]
+}
+
+// normalize makes the code insensitive to case and separators
+func normalize(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// GenerateRecoveryCodes replaces the recovery codes of the user, the codes are returned only once
+func GenerateRecoveryCodes(u *model.User) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashed := make([]model.RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code := newCode()
+		salt := random.String(16)
+		codes = append(codes, code)
+		hashed = append(hashed, model.RecoveryCode{
+			UserID: u.ID,
+			Hash:   model.HashPwd(normalize(code), salt),
+			Salt:   salt,
+		})
+	}
+	if err := db.ReplaceRecoveryCodes(u.ID, hashed); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RemainingRecoveryCodes returns the number of unused recovery codes
+func RemainingRecoveryCodes(u *model.User) (int, error) {
+	codes, err := db.GetRecoveryCodes(u.ID)
+	return len(codes), err
+}
+
+// UseRecoveryCode consumes the recovery code if it matches one of the user
+func UseRecoveryCode(u *model.User, code string) error {
+	code = normalize(code)
+	if code == "" {
+		return errs.RecoveryCodeInvalid
+	}
+	codes, err := db.GetRecoveryCodes(u.ID)
+	if err != nil {
+		return err
+	}
+	for _, c := range codes {
+		if model.HashPwd(code, c.Salt) == c.Hash {
+			return db.DeleteRecoveryCodeById(c.ID)
+		}
+	}
+	return errors.WithStack(errs.RecoveryCodeInvalid)
+}
+
+var policy string
+
+func init() {
+	op.RegisterSettingItemHook(conf.MfaRequired, func(item *model.SettingItem) error {
+		// the pending deadlines are restarted if the policy is changed, but not on startup
+		if policy != "" && policy != item.Value {
+			if err := op.ResetMfaEnrollBy(); err != nil {
+				log.Errorf("failed reset mfa enrolment deadlines: %+v", err)
+			}
+		}
+		policy = item.Value
+		return nil
+	})
+}
@@ -0,0 +1,28 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code := newCode()
+		if len(code) != 17 || code[8] != '-' {
+			t.Fatalf("unexpected code %q", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicated code %q", code)
+		}
+		seen[code] = true
+	}
+	code := newCode()
+	hash := model.HashPwd(normalize(code), "salt")
+	for _, input := range []string{code, " " + code[:8] + code[9:], "  " + code} {
+		if model.HashPwd(normalize(input), "salt") != hash {
+			t.Errorf("%q does not match %q", input, code)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// scopes of api tokens
+const (
+	ScopeFsRead  = "fs:read"
+	ScopeFsWrite = "fs:write"
+	ScopeTasks   = "tasks"
+	ScopeAdmin   = "admin"
+)
+
+var Scopes = []string{ScopeFsRead, ScopeFsWrite, ScopeTasks, ScopeAdmin}
+
+// kinds of api tokens
+const (
+	KindAPIToken = ""
+	// KindAppPassword is a password for webdav and s3 clients, it is not accepted by the api
+	KindAppPassword = "app_password"
+)
+
+// APIToken is a named credential of a user for automation, only the hash of its secret is stored
+type APIToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"index"`
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind" gorm:"index;default:''"`
+	Key        string    `json:"key" gorm:"uniqueIndex;size:64"` // public part of the token, also the s3 access key id
+	Hash       string    `json:"-"`
+	Scopes     string    `json:"scopes"`      // comma separated
+	PathPrefix string    `json:"path_prefix"` // relative to the base path of the user, empty means no restriction
+	Expire     int64     `json:"expire"`      // unix timestamp, 0 means never
+	LastUsed   time.Time `json:"last_used"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (t *APIToken) Expired() bool {
+	return t.Expire != 0 && t.Expire < time.Now().Unix()
+}
+
+// HasScope reports whether the token is granted scope, admin implies every scope and fs:write implies fs:read
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range strings.Split(t.Scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s == scope || s == ScopeAdmin || (s == ScopeFsWrite && scope == ScopeFsRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether the user has the permissions of the scope, so that a token never exceeds its owner
+func (u *User) CanGrant(scope string) bool {
+	switch scope {
+	case ScopeAdmin:
+		return u.IsAdmin()
+	case ScopeFsWrite:
+		return u.CanWrite() || u.CanRename() || u.CanMove() || u.CanCopy() || u.CanRemove()
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Feed is a RSS/Atom subscription whose new items are added as offline download tasks
+type Feed struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Name     string `json:"name" binding:"required"`
+	URL      string `json:"url" binding:"required"`
+	Interval int    `json:"interval"` // poll interval in minutes
+	Disabled bool   `json:"disabled"`
+	// filters
+	Include string `json:"include"`  // regex the title must match, empty means all
+	Exclude string `json:"exclude"`  // regex the title must not match
+	MinSize int64  `json:"min_size"` // in bytes, 0 means no limit, items without size are not filtered
+	MaxSize int64  `json:"max_size"` // in bytes, 0 means no limit
+	MaxAge  int    `json:"max_age"`  // in hours, items published earlier are skipped, 0 means no limit
+	// download
+	DstDirPath   string `json:"dst_dir_path" binding:"required"`
+	Tool         string `json:"tool" binding:"required"`
+	DeletePolicy string `json:"delete_policy"`
+
+	LastFetched time.Time `json:"last_fetched"`
+	Status      string    `json:"status"`
+}
+
+// FeedItem records an item of a feed that has been enqueued
+type FeedItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	FeedID    uint      `json:"feed_id" gorm:"uniqueIndex:idx_feed_guid"`
+	GUID      string    `json:"guid" gorm:"uniqueIndex:idx_feed_guid;size:512"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url" gorm:"type:text"`
+	Size      int64     `json:"size"`
+	Published time.Time `json:"published"`
+	CreatedAt time.Time `json:"created_at"`
+}
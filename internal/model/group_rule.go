@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// GroupRule maps the members of a group of an external directory, such as ldap or oidc, to alist permissions
+type GroupRule struct {
+	Group      string `json:"group"`
+	Permission int32  `json:"permission"`
+	BasePath   string `json:"base_path"`
+	// Admin grants all the permissions, the role of the user is never changed as only one admin exists
+	Admin bool `json:"admin"`
+}
+
+// AllPermissions is the permission with all the bits of the user permissions set
+const AllPermissions int32 = 1<<10 - 1
+
+// ApplyGroupRules sets the permission and base path of the user by the rules matching the groups case-insensitively,
+// permissions of all the matched rules are combined, the base path is taken from the first matched rule having one.
+// the defaults are used if no rule matches, it reports whether the user is changed. the admin and guest are left as they are
+func ApplyGroupRules(rules []GroupRule, groups []string, defPermission int32, defBasePath string, user *User) bool {
+	if user.IsAdmin() || user.IsGuest() {
+		return false
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, strings.ToLower(g))
+	}
+	var permission int32
+	basePath := ""
+	matched := false
+	for _, r := range rules {
+		if !utils.SliceContains(names, strings.ToLower(r.Group)) {
+			continue
+		}
+		matched = true
+		permission |= r.Permission
+		if basePath == "" && r.BasePath != "" {
+			basePath = r.BasePath
+		}
+		if r.Admin {
+			permission |= AllPermissions
+		}
+	}
+	if !matched {
+		permission = defPermission
+	}
+	if basePath == "" {
+		basePath = defBasePath
+	}
+	basePath = utils.FixAndCleanPath(basePath)
+	changed := user.Permission != permission || user.BasePath != basePath
+	user.Permission, user.BasePath = permission, basePath
+	return changed
+}
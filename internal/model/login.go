@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// methods of authentication recorded in the login history
+const (
+	LoginMethodPassword = "password"
+	LoginMethodLdap     = "ldap"
+	LoginMethodWebauthn = "webauthn"
+	LoginMethodSSO      = "sso"
+	LoginMethodOIDC     = "oidc"
+	LoginMethodWebDAV   = "webdav"
+	LoginMethodS3       = "s3"
+)
+
+// LoginAttempt is an entry of the login history
+type LoginAttempt struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Username  string    `json:"username" gorm:"index"`
+	IP        string    `json:"ip"`
+	Method    string    `json:"method"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"` // why the attempt failed
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// LoginLock counts the consecutive login failures of an ip or an account
+type LoginLock struct {
+	Key         string `json:"key" gorm:"primaryKey;size:255"` // ip:<ip> or user:<username>
+	Failures    int    `json:"failures"`
+	LastFailure int64  `json:"last_failure"` // unix timestamp
+	LockedUntil int64  `json:"locked_until"` // unix timestamp, 0 if never locked
+}
+
+func (l *LoginLock) Locked() bool {
+	return l.LockedUntil > time.Now().Unix()
+}
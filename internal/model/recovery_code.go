@@ -0,0 +1,9 @@
+package model
+
+// RecoveryCode is a one-time code to login in place of the 2FA code, only its hash is stored
+type RecoveryCode struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Hash   string `json:"-"`
+	Salt   string `json:"-"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// ScimGroup is a group provisioned by an identity provider through scim
+type ScimGroup struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	DisplayName string `json:"display_name" gorm:"unique"`
+	ExternalID  string `json:"external_id"`
+}
+
+// ScimGroupMember records that a user is a member of a scim group
+type ScimGroupMember struct {
+	GroupID uint `json:"group_id" gorm:"primaryKey"`
+	UserID  uint `json:"user_id" gorm:"primaryKey"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Session is a login of a user, the access tokens issued for it are valid until it is revoked or expired
+type Session struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	SessionID   string `json:"session_id" gorm:"uniqueIndex;size:64"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	PwdTS       int64  `json:"-"` // the password timestamp of the user when logging in
+	RefreshHash string `json:"-"`
+	// the hash of the rotated refresh token, presenting it again means the token is stolen
+	PrevRefreshHash string    `json:"-"`
+	Device          string    `json:"device"`
+	IP              string    `json:"ip"`
+	Method          string    `json:"method"` // the login method, see LoginMethodPassword
+	Expire          int64     `json:"expire"` // unix timestamp, extended on each refresh
+	LastSeen        time.Time `json:"last_seen"`
+	// set if logged in with an oidc provider, used by the back-channel logout
+	SSOProvider string    `json:"sso_provider"`
+	SSOSubject  string    `json:"-"`
+	SSOSid      string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (s *Session) Expired() bool {
+	return s.Expire < time.Now().Unix()
+}
@@ -11,6 +11,7 @@ const (
 	SSO
 	LDAP
 	S3
+	SCIM
 )
 
 const (
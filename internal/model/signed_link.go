@@ -0,0 +1,73 @@
+package model
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SignedLink is a download link of a single path minted by a user, with its own restrictions
+type SignedLink struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Key     string `json:"key" gorm:"uniqueIndex;size:64"`
+	UserID  uint   `json:"user_id" gorm:"index"`
+	Path    string `json:"path"`
+	Expire  int64  `json:"expire"`   // unix timestamp, 0 means never
+	IPs     string `json:"ips"`      // allowed client ips or cidrs, comma separated, empty means any
+	Referer string `json:"referer"`  // allowed referer prefix, empty means any
+	MaxUses int    `json:"max_uses"` // 0 means unlimited
+	Uses    int    `json:"uses"`
+	Remark  string `json:"remark"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (l *SignedLink) Expired() bool {
+	return l.Expire != 0 && l.Expire < time.Now().Unix()
+}
+
+func (l *SignedLink) Exhausted() bool {
+	return l.MaxUses > 0 && l.Uses >= l.MaxUses
+}
+
+// AllowIP reports whether ip matches one of the ips or cidrs of the link
+func (l *SignedLink) AllowIP(ip string) bool {
+	if l.IPs == "" {
+		return true
+	}
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+	for _, s := range strings.Split(l.IPs, ",") {
+		s = strings.TrimSpace(s)
+		if strings.Contains(s, "/") {
+			if _, cidr, err := net.ParseCIDR(s); err == nil && cidr.Contains(clientIP) {
+				return true
+			}
+		} else if allowed := net.ParseIP(s); allowed != nil && allowed.Equal(clientIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowReferer requires the same scheme and host as the configured referer, then the path prefix
+func (l *SignedLink) AllowReferer(referer string) bool {
+	if l.Referer == "" {
+		return true
+	}
+	allowed, err := url.Parse(l.Referer)
+	if err != nil || allowed.Host == "" {
+		return false
+	}
+	u, err := url.Parse(referer)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, allowed.Scheme) || !strings.EqualFold(u.Host, allowed.Host) {
+		return false
+	}
+	return strings.HasPrefix(u.Path, allowed.Path)
+}
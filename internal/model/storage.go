@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Storage struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`                        // unique key
@@ -16,6 +19,8 @@ type Storage struct {
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Balance
+	MountOptions
 }
 
 type Sort struct {
@@ -31,6 +36,70 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+// strategies to select among the storages of a balance group
+const (
+	BalanceRoundRobin   = "round_robin"
+	BalanceWeighted     = "weighted"
+	BalanceLeastLatency = "least_latency"
+	BalanceSticky       = "sticky" // by the ip of the client
+)
+
+type Balance struct {
+	// the strategy of the group is the one of the storage without the .balance suffix
+	BalanceStrategy string `json:"balance_strategy"`
+	BalanceWeight   int    `json:"balance_weight"` // used by the weighted strategy, 1 if not positive
+}
+
+// MountOptions are enforced regardless of the driver
+type MountOptions struct {
+	ReadOnly bool `json:"read_only"` // the writes are rejected
+	Hidden   bool `json:"hidden"`    // not listed in the parent, but still accessible by the path, e.g. as a copy target
+	// who can see and access the storage besides the admins, separated by commas or new lines,
+	// each one is a username, group:<scim group> or role:<general|guest|admin>, everyone can if empty
+	VisibleTo string `json:"visible_to" gorm:"type:text"`
+}
+
+var roleNames = map[string]int{"general": GENERAL, "guest": GUEST, "admin": ADMIN}
+
+// IsVisibleTo reports whether the user can see the storage, groups returns the names of the groups of the user,
+// it's only called if needed. nil user means the access is internal, e.g. by a task, which is always allowed
+func (m MountOptions) IsVisibleTo(user *User, groups func() []string) bool {
+	if user == nil || user.IsAdmin() || strings.TrimSpace(m.VisibleTo) == "" {
+		return true
+	}
+	var userGroups []string
+	loaded := false
+	for _, entry := range strings.FieldsFunc(m.VisibleTo, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case strings.HasPrefix(entry, "role:"):
+			if role, ok := roleNames[strings.ToLower(strings.TrimPrefix(entry, "role:"))]; ok && role == user.Role {
+				return true
+			}
+		case strings.HasPrefix(entry, "group:"):
+			if !loaded {
+				userGroups, loaded = groups(), true
+			}
+			name := strings.TrimPrefix(entry, "group:")
+			for _, g := range userGroups {
+				if strings.EqualFold(g, name) {
+					return true
+				}
+			}
+		case entry == user.Username:
+			return true
+		}
+	}
+	return false
+}
+
+// Space is the space of a storage in bytes
+type Space struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"`
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
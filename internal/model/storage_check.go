@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// StorageCheck is an entry of the health check history of a storage
+type StorageCheck struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StorageID uint      `json:"storage_id" gorm:"index"`
+	Success   bool      `json:"success"`
+	Reinit    bool      `json:"reinit"`  // if the storage is reinitialized by the check
+	Status    string    `json:"status"`  // the error if failed
+	Latency   int64     `json:"latency"` // milliseconds
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -44,7 +44,13 @@ type User struct {
 	//   9: webdav write
 	Permission int32  `json:"permission"`
 	OtpSecret  string `json:"-"`
-	SsoID      string `json:"sso_id"` // unique by sso platform
+	SsoID      string `json:"sso_id"`  // unique by sso platform
+	LdapDN     string `json:"ldap_dn"` // set if the user is managed by ldap
+	// the deadline to enroll 2FA if it is required by the policy, unix timestamp, 0 if not started
+	MfaEnrollBy int64 `json:"mfa_enroll_by"`
+	// ips or cidrs separated by new lines or commas, the user can only access from the allowed ones if any
+	AllowedIPs string `json:"allowed_ips" gorm:"type:text"`
+	DeniedIPs  string `json:"denied_ips" gorm:"type:text"`
 	Authn      string `gorm:"type:text" json:"-"`
 }
 
@@ -7,4 +7,5 @@ import (
 	_ "github.com/alist-org/alist/v3/internal/offline_download/pikpak"
 	_ "github.com/alist-org/alist/v3/internal/offline_download/qbit"
 	_ "github.com/alist-org/alist/v3/internal/offline_download/transmission"
+	_ "github.com/alist-org/alist/v3/internal/offline_download/ytdlp"
 )
@@ -78,7 +78,45 @@ func (a *QBittorrent) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
+func (a *QBittorrent) SelectFiles(task *tool.DownloadTask) (bool, error) {
+	files, err := a.client.GetFiles(task.GID)
+	if err != nil {
+		return false, err
+	}
+	// metadata of magnet links is not downloaded yet
+	if len(files) == 0 {
+		return false, nil
+	}
+	var unwanted []int
+	for _, file := range files {
+		if !task.MatchFile(file.Name) {
+			unwanted = append(unwanted, file.Index)
+		}
+	}
+	if len(unwanted) == len(files) {
+		return false, errors.Errorf("no file matches %s", task.FileFilter)
+	}
+	if len(unwanted) == 0 {
+		return true, nil
+	}
+	return true, a.client.SetFilePriority(task.GID, unwanted, 0)
+}
+
+func (a *QBittorrent) SeedTime() int {
+	return setting.GetInt(conf.QbittorrentSeedtime, 0)
+}
+
+func (a *QBittorrent) Ratio(task *tool.DownloadTask) (float64, error) {
+	info, err := a.client.GetInfo(task.GID)
+	if err != nil {
+		return 0, err
+	}
+	return info.Ratio, nil
+}
+
 var _ tool.Tool = (*QBittorrent)(nil)
+var _ tool.FileSelector = (*QBittorrent)(nil)
+var _ tool.Seeder = (*QBittorrent)(nil)
 
 func init() {
 	tool.Tools.Add(&QBittorrent{})
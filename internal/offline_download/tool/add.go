@@ -5,6 +5,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/task"
 	"path/filepath"
+	"regexp"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -27,41 +28,32 @@ type AddURLArgs struct {
 	DstDirPath   string
 	Tool         string
 	DeletePolicy DeletePolicy
+	// Format is the format selector for media extractors like yt-dlp, empty means the tool's default
+	Format string
+	// Rename is the template used to rename the downloaded files, see RenderName
+	Rename string
+	// Rules route the downloaded files to other directories by name, see RouteRule
+	Rules []RouteRule
+	// SeedTime in minutes and SeedRatio override the default seed time of the torrent tool,
+	// seeding stops when either is reached
+	SeedTime  *int
+	SeedRatio float64
+	// FileFilter is a regex, only the files of a torrent whose path matches are downloaded
+	FileFilter string
+	// Extract the downloaded zip and tar archives before transfer
+	Extract bool
+}
+
+// CheckURLArgs checks the args could be added without adding a task, so that a batch is checked as a whole
+func CheckURLArgs(ctx context.Context, args *AddURLArgs) error {
+	_, err := checkArgs(ctx, args)
+	return err
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskInfoWithCreator, error) {
-	// get tool
-	tool, err := Tools.Get(args.Tool)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed get tool")
-	}
-	// check tool is ready
-	if !tool.IsReady() {
-		// try to init tool
-		if _, err := tool.Init(); err != nil {
-			return nil, errors.Wrapf(err, "failed init tool %s", args.Tool)
-		}
-	}
-	// check storage
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(args.DstDirPath)
-	if err != nil {
-		return nil, errors.WithMessage(err, "failed get storage")
-	}
-	// check is it could upload
-	if storage.Config().NoUpload {
-		return nil, errors.WithStack(errs.UploadNotSupported)
-	}
-	// check path is valid
-	obj, err := op.Get(ctx, storage, dstDirActualPath)
+	tool, err := checkArgs(ctx, args)
 	if err != nil {
-		if !errs.IsObjectNotFound(err) {
-			return nil, errors.WithMessage(err, "failed get object")
-		}
-	} else {
-		if !obj.IsDir() {
-			// can't add to a file
-			return nil, errors.WithStack(errs.NotFolder)
-		}
+		return nil, err
 	}
 
 	uid := uuid.NewString()
@@ -89,8 +81,81 @@ func AddURL(ctx context.Context, args *AddURLArgs) (task.TaskInfoWithCreator, er
 		TempDir:      tempDir,
 		DeletePolicy: deletePolicy,
 		Toolname:     args.Tool,
+		Format:       args.Format,
+		Rename:       args.Rename,
+		Rules:        args.Rules,
+		SeedTime:     args.SeedTime,
+		SeedRatio:    args.SeedRatio,
+		FileFilter:   args.FileFilter,
+		Extract:      args.Extract,
 		tool:         tool,
 	}
 	DownloadTaskManager.Add(t)
 	return t, nil
 }
+
+func checkArgs(ctx context.Context, args *AddURLArgs) (Tool, error) {
+	// get tool
+	tool, err := Tools.Get(args.Tool)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get tool")
+	}
+	// check tool is ready
+	if !tool.IsReady() {
+		// try to init tool
+		if _, err := tool.Init(); err != nil {
+			return nil, errors.Wrapf(err, "failed init tool %s", args.Tool)
+		}
+	}
+	if args.FileFilter != "" {
+		if _, ok := tool.(FileSelector); !ok {
+			return nil, errors.Errorf("tool %s does not support selecting files", args.Tool)
+		}
+		if _, err := regexp.Compile(args.FileFilter); err != nil {
+			return nil, errors.Wrapf(err, "invalid file filter")
+		}
+	}
+	if err := checkDstDir(ctx, args.DstDirPath); err != nil {
+		return nil, err
+	}
+	for _, rule := range args.Rules {
+		if err := checkDstDir(ctx, rule.DstDirPath); err != nil {
+			return nil, errors.WithMessagef(err, "invalid rule %s", rule.Pattern)
+		}
+	}
+	return tool, nil
+}
+
+// checkDstDir check the dst dir could be uploaded to
+func checkDstDir(ctx context.Context, dstDirPath string) error {
+	// check storage
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	// the storages hidden from the user are not found, like in fs
+	user, _ := ctx.Value("user").(*model.User)
+	if !op.CanSeeStorage(user, storage.GetStorage()) {
+		return errors.WithMessage(errs.StorageNotFound, "failed get storage")
+	}
+	// check is it could upload
+	if storage.Config().NoUpload {
+		return errors.WithStack(errs.UploadNotSupported)
+	}
+	if storage.GetStorage().ReadOnly {
+		return errors.WithStack(errs.StorageReadOnly)
+	}
+	// check path is valid
+	obj, err := op.Get(ctx, storage, dstDirActualPath)
+	if err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return errors.WithMessage(err, "failed get object")
+		}
+	} else {
+		if !obj.IsDir() {
+			// can't add to a file
+			return errors.WithStack(errs.NotFolder)
+		}
+	}
+	return nil
+}
@@ -45,6 +45,21 @@ type GetFileser interface {
 	GetFiles(task *DownloadTask) []File
 }
 
+// FileSelector is implemented by the torrent tools that can skip some files of a torrent
+type FileSelector interface {
+	// SelectFiles skips the files not matched by task.FileFilter,
+	// return false if the file list of the torrent is not available yet
+	SelectFiles(task *DownloadTask) (bool, error)
+}
+
+// Seeder is implemented by the torrent tools that keep seeding after the download completed
+type Seeder interface {
+	// SeedTime return the default seed time in minutes, negative means seeding until removed manually
+	SeedTime() int
+	// Ratio return the share ratio of the download task
+	Ratio(task *DownloadTask) (float64, error)
+}
+
 type File struct {
 	// ReadCloser for http client
 	ReadCloser io.ReadCloser
@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type BatchFormat string
+
+const (
+	BatchText BatchFormat = "text"
+	BatchCSV  BatchFormat = "csv"
+	BatchJSON BatchFormat = "json"
+)
+
+// BatchEntry is one line of a batch import, empty fields fall back to the defaults of the request
+type BatchEntry struct {
+	URL          string       `json:"url"`
+	DstDirPath   string       `json:"path"`
+	Tool         string       `json:"tool"`
+	Rename       string       `json:"rename"`
+	DeletePolicy DeletePolicy `json:"delete_policy"`
+}
+
+var batchCSVColumns = []string{"url", "path", "tool", "rename", "delete_policy"}
+
+// ParseBatch parses a list of urls (http links, magnets, ...) in the given format.
+//
+//   - text: one url per line, blank lines and lines starting with # are ignored
+//   - csv: columns url,path,tool,rename,delete_policy, the header line is optional
+//   - json: an array of BatchEntry
+func ParseBatch(format BatchFormat, content string) ([]BatchEntry, error) {
+	var entries []BatchEntry
+	switch format {
+	case BatchText, "":
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, BatchEntry{URL: line})
+		}
+	case BatchCSV:
+		r := csv.NewReader(strings.NewReader(content))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		r.Comment = '#'
+		columns := batchCSVColumns
+		for first := true; ; first = false {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse csv")
+			}
+			if first && strings.EqualFold(strings.TrimSpace(record[0]), "url") {
+				columns = make([]string, len(record))
+				for i := range record {
+					columns[i] = strings.ToLower(strings.TrimSpace(record[i]))
+				}
+				continue
+			}
+			var entry BatchEntry
+			for i, v := range record {
+				if i >= len(columns) {
+					break
+				}
+				v = strings.TrimSpace(v)
+				switch columns[i] {
+				case "url":
+					entry.URL = v
+				case "path":
+					entry.DstDirPath = v
+				case "tool":
+					entry.Tool = v
+				case "rename":
+					entry.Rename = v
+				case "delete_policy":
+					entry.DeletePolicy = DeletePolicy(v)
+				}
+			}
+			if entry.URL == "" {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	case BatchJSON:
+		if err := json.Unmarshal([]byte(content), &entries); err != nil {
+			return nil, errors.Wrap(err, "failed to parse json")
+		}
+		for i := range entries {
+			if entries[i].URL == "" {
+				return nil, errors.Errorf("entry %d has no url", i)
+			}
+		}
+	default:
+		return nil, errors.Errorf("unknown batch format: %s", format)
+	}
+	return entries, nil
+}
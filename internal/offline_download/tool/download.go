@@ -2,11 +2,11 @@ package tool
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"time"
 
-	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
-	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -20,11 +20,19 @@ type DownloadTask struct {
 	TempDir           string       `json:"temp_dir"`
 	DeletePolicy      DeletePolicy `json:"delete_policy"`
 	Toolname          string       `json:"toolname"`
+	Format            string       `json:"format,omitempty"`
+	Rename            string       `json:"rename,omitempty"`
+	Rules             []RouteRule  `json:"rules,omitempty"`
+	SeedTime          *int         `json:"seed_time,omitempty"`
+	SeedRatio         float64      `json:"seed_ratio,omitempty"`
+	FileFilter        string       `json:"file_filter,omitempty"`
+	Extract           bool         `json:"extract,omitempty"`
 	Status            string       `json:"-"`
 	Signal            chan int     `json:"-"`
 	GID               string       `json:"-"`
 	tool              Tool
 	callStatusRetried int
+	filesSelected     bool
 }
 
 func (t *DownloadTask) Run() error {
@@ -90,32 +98,60 @@ outer:
 		return nil
 	}
 	t.Status = "offline download completed, maybe transferring"
-	// hack for qBittorrent
-	if t.tool.Name() == "qBittorrent" {
-		seedTime := setting.GetInt(conf.QbittorrentSeedtime, 0)
-		if seedTime >= 0 {
-			t.Status = "offline download completed, waiting for seeding"
-			<-time.After(time.Minute * time.Duration(seedTime))
-			err := t.tool.Remove(t)
-			if err != nil {
-				log.Errorln(err.Error())
-			}
-		}
+	if seeder, ok := t.tool.(Seeder); ok {
+		t.seed(seeder)
 	}
+	return nil
+}
 
-	if t.tool.Name() == "transmission" {
-		// hack for transmission
-		seedTime := setting.GetInt(conf.TransmissionSeedtime, 0)
-		if seedTime >= 0 {
-			t.Status = "offline download completed, waiting for seeding"
-			<-time.After(time.Minute * time.Duration(seedTime))
-			err := t.tool.Remove(t)
+// seed waits until the seed time or the seed ratio is reached, then removes the torrent
+func (t *DownloadTask) seed(seeder Seeder) {
+	seedTime, seedRatio := seeder.SeedTime(), 0.0
+	if t.SeedTime != nil || t.SeedRatio > 0 {
+		seedTime, seedRatio = -1, t.SeedRatio
+		if t.SeedTime != nil {
+			seedTime = *t.SeedTime
+		}
+	}
+	if seedTime < 0 && seedRatio <= 0 {
+		// keep seeding until removed manually
+		return
+	}
+	limit := time.Minute * time.Duration(seedTime)
+	start := time.Now()
+outer:
+	for {
+		elapsed := time.Since(start)
+		if seedTime >= 0 && elapsed >= limit {
+			break
+		}
+		status := "offline download completed, waiting for seeding"
+		if seedRatio > 0 {
+			ratio, err := seeder.Ratio(t)
 			if err != nil {
-				log.Errorln(err.Error())
+				log.Errorf("failed to get ratio of %s: %+v", t.ID, err)
+			} else if ratio >= seedRatio {
+				break
+			} else {
+				status += fmt.Sprintf(", ratio %.2f/%.2f", ratio, seedRatio)
 			}
 		}
+		wait := seedCheckInterval
+		if seedTime >= 0 {
+			wait = min(wait, limit-elapsed)
+			status += fmt.Sprintf(", %s left", (limit - elapsed).Round(time.Second))
+		}
+		t.Status = status
+		select {
+		case <-t.CtxDone():
+			// canceled while seeding, remove it as well
+			break outer
+		case <-time.After(wait):
+		}
+	}
+	if err := t.tool.Remove(t); err != nil {
+		log.Errorln(err.Error())
 	}
-	return nil
 }
 
 // Update download status, return true if download completed
@@ -130,6 +166,14 @@ func (t *DownloadTask) Update() (bool, error) {
 		return true, errors.Errorf("failed to get status of %s, retried %d times", t.ID, t.callStatusRetried)
 	}
 	t.callStatusRetried = 0
+	if t.FileFilter != "" && !t.filesSelected {
+		if selector, ok := t.tool.(FileSelector); ok {
+			t.filesSelected, err = selector.SelectFiles(t)
+			if err != nil {
+				return true, errors.WithMessage(err, "failed to select files")
+			}
+		}
+	}
 	t.SetProgress(info.Progress)
 	t.Status = fmt.Sprintf("[%s]: %s", t.tool.Name(), info.Status)
 	if info.NewGID != "" {
@@ -168,15 +212,32 @@ func (t *DownloadTask) Complete() error {
 			return errors.Wrapf(err, "failed to get files")
 		}
 	}
+	if t.FileFilter != "" {
+		files, err = t.filterFiles(files)
+		if err != nil {
+			return err
+		}
+	}
+	if t.Extract {
+		files, err = extractFiles(t.Ctx(), files)
+		if err != nil {
+			return errors.WithMessage(err, "failed to extract files")
+		}
+	}
 	// upload files
 	for i := range files {
 		file := files[i]
+		name := file.Name
+		if name == "" {
+			name = filepath.Base(file.Path)
+		}
 		TransferTaskManager.Add(&TransferTask{
 			TaskWithCreator: task.TaskWithCreator{
 				Creator: t.Creator,
 			},
 			file:         file,
-			DstDirPath:   t.DstDirPath,
+			Name:         RenderName(t.Rename, name, i+1),
+			DstDirPath:   Route(t.Rules, name, t.DstDirPath),
 			TempDir:      t.TempDir,
 			DeletePolicy: t.DeletePolicy,
 			FileDir:      file.Path,
@@ -185,6 +246,37 @@ func (t *DownloadTask) Complete() error {
 	return nil
 }
 
+// filterFiles drops the files outside FileFilter, which may be left partially downloaded by the tool
+func (t *DownloadTask) filterFiles(files []File) ([]File, error) {
+	reg, err := regexp.Compile(t.FileFilter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid file filter")
+	}
+	var res []File
+	for _, file := range files {
+		rel, err := filepath.Rel(t.TempDir, file.Path)
+		if err != nil {
+			rel = file.Name
+		}
+		if reg.MatchString(filepath.ToSlash(rel)) {
+			res = append(res, file)
+		}
+	}
+	return res, nil
+}
+
+// MatchFile reports whether the file at path inside the torrent should be downloaded
+func (t *DownloadTask) MatchFile(path string) bool {
+	if t.FileFilter == "" {
+		return true
+	}
+	reg, err := regexp.Compile(t.FileFilter)
+	if err != nil {
+		return false
+	}
+	return reg.MatchString(path)
+}
+
 func (t *DownloadTask) GetName() string {
 	return fmt.Sprintf("download %s to (%s)", t.Url, t.DstDirPath)
 }
@@ -193,4 +285,6 @@ func (t *DownloadTask) GetStatus() string {
 	return t.Status
 }
 
+const seedCheckInterval = time.Second * 30
+
 var DownloadTaskManager *tache.Manager[*DownloadTask]
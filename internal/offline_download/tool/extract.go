@@ -0,0 +1,191 @@
+package tool
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+// extractLimit bounds the total size and the entries extracted from the archives of a download against archive bombs
+type extractLimit struct {
+	size    int64
+	entries int
+}
+
+func newExtractLimit() *extractLimit {
+	l := &extractLimit{
+		size:    int64(setting.GetInt(conf.OfflineDownloadExtractMaxSize, 10240)) * 1024 * 1024,
+		entries: setting.GetInt(conf.OfflineDownloadExtractMaxEntries, 10000),
+	}
+	if l.size <= 0 {
+		l.size = math.MaxInt64
+	}
+	if l.entries <= 0 {
+		l.entries = math.MaxInt
+	}
+	return l
+}
+
+func (l *extractLimit) entry() error {
+	if l.entries == 0 {
+		return errors.Errorf("too many entries in archives, the limit is %d", setting.GetInt(conf.OfflineDownloadExtractMaxEntries, 10000))
+	}
+	l.entries--
+	return nil
+}
+
+// extractFiles extracts the zip and tar archives in files next to them,
+// the archives are replaced by the extracted files and kept on disk for seeding
+func extractFiles(ctx context.Context, files []File) ([]File, error) {
+	var res []File
+	limit := newExtractLimit()
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ext := archiveExt(file.Path)
+		if ext == "" {
+			res = append(res, file)
+			continue
+		}
+		dir := file.Path[:len(file.Path)-len(ext)]
+		var err error
+		if strings.EqualFold(ext, ".zip") {
+			err = extractZip(file.Path, dir, limit)
+		} else {
+			err = extractTar(file.Path, dir, !strings.EqualFold(ext, ".tar"), limit)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to extract %s", file.Name)
+		}
+		extracted, err := GetFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, extracted...)
+	}
+	return res, nil
+}
+
+var archiveExts = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+func archiveExt(path string) string {
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return path[len(path)-len(ext):]
+		}
+	}
+	return ""
+}
+
+// safeJoin joins name to dir, refusing names escaping dir
+func safeJoin(dir, name string) (string, error) {
+	p := filepath.Join(dir, name)
+	if p != dir && !strings.HasPrefix(p, dir+string(os.PathSeparator)) {
+		return "", errors.Errorf("illegal file path in archive: %s", name)
+	}
+	return p, nil
+}
+
+// writeFile writes r to path within the size left by the limit
+func writeFile(path string, r io.Reader, limit *extractLimit) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(f, io.LimitReader(r, limit.size))
+	limit.size -= n
+	if err != nil {
+		return err
+	}
+	if limit.size == 0 {
+		if m, _ := io.ReadFull(r, make([]byte, 1)); m > 0 {
+			return errors.Errorf("archives are larger than the limit of %d MB", setting.GetInt(conf.OfflineDownloadExtractMaxSize, 10240))
+		}
+	}
+	return nil
+}
+
+func extractZip(src, dir string, limit *extractLimit) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if err := limit.entry(); err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		p, err := safeJoin(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(p, rc, limit)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(src, dir string, gzipped bool, limit *extractLimit) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if gzipped {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := limit.entry(); err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		p, err := safeJoin(dir, h.Name)
+		if err != nil {
+			return err
+		}
+		if err = writeFile(p, tr, limit); err != nil {
+			return err
+		}
+	}
+}
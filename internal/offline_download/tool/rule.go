@@ -0,0 +1,52 @@
+package tool
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteRule sends the downloaded files whose name matches Pattern to DstDirPath instead of the task's destination
+type RouteRule struct {
+	// Pattern is a shell pattern matched case-insensitively against the file name, e.g. *.mkv
+	Pattern    string `json:"pattern"`
+	DstDirPath string `json:"path"`
+}
+
+func (r RouteRule) Match(name string) bool {
+	ok, _ := path.Match(strings.ToLower(r.Pattern), strings.ToLower(name))
+	return ok
+}
+
+// Route return the destination of the first rule matching name, or def if none matches
+func Route(rules []RouteRule, name string, def string) string {
+	for _, rule := range rules {
+		if rule.Match(name) {
+			return rule.DstDirPath
+		}
+	}
+	return def
+}
+
+// RenderName renders a rename template for a downloaded file.
+// Supported placeholders: {name} the file name without extension, {ext} the extension without dot,
+// {index} the 1-based index of the file in the task, {date} the current date as 2006-01-02.
+// An empty template keeps the original name.
+func RenderName(tmpl string, name string, index int) string {
+	if tmpl == "" {
+		return name
+	}
+	ext := path.Ext(name)
+	r := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(name, ext),
+		"{ext}", strings.TrimPrefix(ext, "."),
+		"{index}", strconv.Itoa(index),
+		"{date}", time.Now().Format("2006-01-02"),
+	)
+	newName := path.Base(r.Replace(tmpl))
+	if newName == "." || newName == "/" || newName == ".." {
+		return name
+	}
+	return newName
+}
@@ -0,0 +1,55 @@
+package tool_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+)
+
+func TestRoute(t *testing.T) {
+	rules := []tool.RouteRule{
+		{Pattern: "*.mkv", DstDirPath: "/media/movies"},
+		{Pattern: "*.flac", DstDirPath: "/media/music"},
+	}
+	tests := map[string]string{
+		"a.mkv":      "/media/movies",
+		"B.MKV":      "/media/movies",
+		"song.flac":  "/media/music",
+		"readme.txt": "/dst",
+	}
+	for name, want := range tests {
+		if got := tool.Route(rules, name, "/dst"); got != want {
+			t.Errorf("Route(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	if got := tool.RenderName("", "a.mkv", 1); got != "a.mkv" {
+		t.Errorf("got %s", got)
+	}
+	if got := tool.RenderName("talk-{index}-{name}.{ext}", "keynote.mp4", 2); got != "talk-2-keynote.mp4" {
+		t.Errorf("got %s", got)
+	}
+	if got := tool.RenderName("../{name}", "a.mkv", 1); got != "a" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestParseBatch(t *testing.T) {
+	entries, err := tool.ParseBatch(tool.BatchText, "# comment\nhttps://a/b.zip\n\nmagnet:?xt=urn:btih:abc\n")
+	if err != nil || len(entries) != 2 || entries[1].URL != "magnet:?xt=urn:btih:abc" {
+		t.Fatalf("text: %+v %v", entries, err)
+	}
+	entries, err = tool.ParseBatch(tool.BatchCSV, "url,path,tool\nhttps://a/b.zip,/movies,aria2\nhttps://a/c.zip\n")
+	if err != nil || len(entries) != 2 || entries[0].DstDirPath != "/movies" || entries[0].Tool != "aria2" {
+		t.Fatalf("csv: %+v %v", entries, err)
+	}
+	entries, err = tool.ParseBatch(tool.BatchJSON, `[{"url":"https://a/b.zip","rename":"{name}.bak"}]`)
+	if err != nil || len(entries) != 1 || entries[0].Rename != "{name}.bak" {
+		t.Fatalf("json: %+v %v", entries, err)
+	}
+	if _, err = tool.ParseBatch(tool.BatchJSON, `[{"path":"/a"}]`); err == nil {
+		t.Fatal("json without url should fail")
+	}
+}
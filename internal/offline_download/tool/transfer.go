@@ -18,6 +18,7 @@ import (
 type TransferTask struct {
 	task.TaskWithCreator
 	FileDir      string       `json:"file_dir"`
+	Name         string       `json:"name,omitempty"`
 	DstDirPath   string       `json:"dst_dir_path"`
 	TempDir      string       `json:"temp_dir"`
 	DeletePolicy DeletePolicy `json:"delete_policy"`
@@ -37,7 +38,11 @@ func (t *TransferTask) Run() error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	mimetype := utils.GetMimeType(t.file.Path)
+	name := t.Name
+	if name == "" {
+		name = filepath.Base(t.file.Path)
+	}
+	mimetype := utils.GetMimeType(name)
 	rc, err := t.file.GetReadCloser()
 	if err != nil {
 		return errors.Wrapf(err, "failed to open file %s", t.file.Path)
@@ -45,7 +50,7 @@ func (t *TransferTask) Run() error {
 	s := &stream.FileStream{
 		Ctx: nil,
 		Obj: &model.Object{
-			Name:     filepath.Base(t.file.Path),
+			Name:     name,
 			Size:     t.file.Size,
 			Modified: t.file.Modified,
 			IsFolder: false,
@@ -59,6 +64,11 @@ func (t *TransferTask) Run() error {
 		log.Errorf("find relation directory error: %v", err)
 	}
 	newDistDir := filepath.Join(dstDirActualPath, relDir)
+	// the downloaded file is local, so it's cheap to hash
+	if err := stream.PrepareRapidUpload(storage, s); err != nil {
+		_ = s.Close()
+		return errors.WithMessagef(err, "failed hash %s", t.file.Path)
+	}
 	return op.Put(t.Ctx(), storage, newDistDir, s, t.SetProgress)
 }
 
@@ -169,7 +169,60 @@ func (t *Transmission) Status(task *tool.DownloadTask) (*tool.Status, error) {
 	return s, nil
 }
 
+func (t *Transmission) SelectFiles(task *tool.DownloadTask) (bool, error) {
+	gid, err := strconv.ParseInt(task.GID, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	infos, err := t.client.TorrentGet(context.TODO(), []string{"id", "files"}, []int64{gid})
+	if err != nil {
+		return false, err
+	}
+	// metadata of magnet links is not downloaded yet
+	if len(infos) < 1 || len(infos[0].Files) == 0 {
+		return false, nil
+	}
+	files := infos[0].Files
+	var unwanted []int64
+	for i, file := range files {
+		if !task.MatchFile(file.Name) {
+			unwanted = append(unwanted, int64(i))
+		}
+	}
+	if len(unwanted) == len(files) {
+		return false, errors.Errorf("no file matches %s", task.FileFilter)
+	}
+	if len(unwanted) == 0 {
+		return true, nil
+	}
+	return true, t.client.TorrentSet(context.TODO(), transmissionrpc.TorrentSetPayload{
+		IDs:           []int64{gid},
+		FilesUnwanted: unwanted,
+	})
+}
+
+func (t *Transmission) SeedTime() int {
+	return setting.GetInt(conf.TransmissionSeedtime, 0)
+}
+
+func (t *Transmission) Ratio(task *tool.DownloadTask) (float64, error) {
+	gid, err := strconv.ParseInt(task.GID, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	infos, err := t.client.TorrentGet(context.TODO(), []string{"id", "uploadRatio"}, []int64{gid})
+	if err != nil {
+		return 0, err
+	}
+	if len(infos) < 1 || infos[0].UploadRatio == nil {
+		return 0, fmt.Errorf("failed get ratio, wrong gid: %s", task.GID)
+	}
+	return *infos[0].UploadRatio, nil
+}
+
 var _ tool.Tool = (*Transmission)(nil)
+var _ tool.FileSelector = (*Transmission)(nil)
+var _ tool.Seeder = (*Transmission)(nil)
 
 func init() {
 	tool.Tools.Add(&Transmission{})
@@ -0,0 +1,61 @@
+package ytdlp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+)
+
+// progressReg matches lines like
+// [download]  42.1% of ~ 120.35MiB at    2.10MiB/s ETA 00:34 (frag 12/40)
+var progressReg = regexp.MustCompile(`^\[download\]\s+([\d.]+)%\s+of\s+~?\s*(\S+)(?:\s+in\s+\S+)?(?:\s+at\s+(.+?))?(?:\s+ETA\s+(\S+))?(?:\s+\(.*\))?$`)
+
+func buildArgs(url, tempDir, format, extra string) []string {
+	args := []string{
+		"--newline",
+		"--no-colors",
+		"--no-part",
+		"--restrict-filenames",
+		"-o", filepath.Join(tempDir, "%(title)s [%(id)s].%(ext)s"),
+	}
+	if format != "" {
+		args = append(args, "-f", format)
+	}
+	for _, line := range strings.Split(extra, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, line)
+	}
+	// "--" stops option parsing so a crafted url can't be taken as a flag
+	return append(args, "--", url)
+}
+
+// parseLine parses a progress line of yt-dlp output, return nil if the line is not a progress line
+func parseLine(line string) *tool.Status {
+	m := progressReg.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return nil
+	}
+	progress, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil
+	}
+	status := fmt.Sprintf("[yt-dlp]: downloading %s of %s", m[1]+"%", m[2])
+	if m[3] != "" && !strings.HasPrefix(m[3], "Unknown") {
+		status += " at " + strings.TrimSpace(m[3])
+	}
+	if m[4] != "" && m[4] != "Unknown" {
+		status += ", ETA " + m[4]
+	}
+	return &tool.Status{
+		Progress:  progress,
+		Completed: progress >= 100,
+		Status:    status,
+	}
+}
@@ -0,0 +1,27 @@
+package ytdlp
+
+import "testing"
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		progress float64
+		ok       bool
+	}{
+		{"[download]  42.1% of ~ 120.35MiB at    2.10MiB/s ETA 00:34 (frag 12/40)", 42.1, true},
+		{"[download] 100% of   10.00MiB in 00:00:05 at 2.00MiB/s", 100, true},
+		{"[download]   0.0% of 3.51GiB at Unknown B/s ETA Unknown", 0, true},
+		{"[download] Destination: /tmp/a.mp4", 0, false},
+		{"[Merger] Merging formats into \"a.mkv\"", 0, false},
+	}
+	for _, tt := range tests {
+		s := parseLine(tt.line)
+		if (s != nil) != tt.ok {
+			t.Errorf("parseLine(%q) ok = %v, want %v", tt.line, s != nil, tt.ok)
+			continue
+		}
+		if s != nil && s.Progress != tt.progress {
+			t.Errorf("parseLine(%q) progress = %v, want %v", tt.line, s.Progress, tt.progress)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ytdlp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type YtDlp struct {
+	bin string
+}
+
+func (y *YtDlp) Name() string {
+	return "yt-dlp"
+}
+
+func (y *YtDlp) Items() []model.SettingItem {
+	// yt-dlp settings
+	return []model.SettingItem{
+		{Key: conf.YtdlpPath, Value: "yt-dlp", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtdlpDefaultFormat, Value: "bestvideo*+bestaudio/best", Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtdlpArgs, Value: "", Type: conf.TypeText, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE, Help: `extra arguments, one per line`},
+	}
+}
+
+func (y *YtDlp) Init() (string, error) {
+	y.bin = ""
+	bin, err := exec.LookPath(setting.GetStr(conf.YtdlpPath, "yt-dlp"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find yt-dlp binary")
+	}
+	out, err := exec.Command(bin, "--version").Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed get yt-dlp version")
+	}
+	y.bin = bin
+	version := strings.TrimSpace(string(out))
+	log.Infof("using yt-dlp version: %s", version)
+	return fmt.Sprintf("yt-dlp version: %s", version), nil
+}
+
+func (y *YtDlp) IsReady() bool {
+	return y.bin != ""
+}
+
+func (y *YtDlp) AddURL(args *tool.AddUrlArgs) (string, error) {
+	panic("should not be called")
+}
+
+func (y *YtDlp) Remove(task *tool.DownloadTask) error {
+	panic("should not be called")
+}
+
+func (y *YtDlp) Status(task *tool.DownloadTask) (*tool.Status, error) {
+	panic("should not be called")
+}
+
+func (y *YtDlp) Run(task *tool.DownloadTask) error {
+	if err := os.MkdirAll(task.TempDir, os.ModePerm); err != nil {
+		return err
+	}
+	format := task.Format
+	if format == "" {
+		format = setting.GetStr(conf.YtdlpDefaultFormat)
+	}
+	cmd := exec.CommandContext(task.Ctx(), y.bin, buildArgs(task.Url, task.TempDir, format, setting.GetStr(conf.YtdlpArgs))...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start yt-dlp")
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		s := parseLine(scanner.Text())
+		if s == nil {
+			continue
+		}
+		task.SetProgress(s.Progress)
+		task.Status = s.Status
+	}
+	if err := cmd.Wait(); err != nil {
+		if task.Ctx().Err() != nil {
+			return task.Ctx().Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if i := strings.LastIndex(msg, "\n"); i >= 0 {
+			msg = msg[i+1:]
+		}
+		return errors.Wrapf(err, "yt-dlp exited: %s", msg)
+	}
+	return nil
+}
+
+var _ tool.Tool = (*YtDlp)(nil)
+
+func init() {
+	tool.Tools.Add(&YtDlp{})
+}
@@ -0,0 +1,154 @@
+package op
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// APITokenPrefix marks an api token, the full token is APITokenPrefix + key + "-" + secret
+const APITokenPrefix = "alist-pat-"
+
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+func hashAPITokenSecret(secret string) string {
+	return utils.HashData(utils.SHA256, []byte(secret))
+}
+
+func checkScopes(t *model.APIToken) error {
+	scopes := make([]string, 0, len(model.Scopes))
+	for _, s := range strings.Split(t.Scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !utils.SliceContains(model.Scopes, s) {
+			return errors.Errorf("unknown scope: %s", s)
+		}
+		scopes = append(scopes, s)
+	}
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	t.Scopes = strings.Join(scopes, ",")
+	if t.PathPrefix != "" {
+		t.PathPrefix = utils.FixAndCleanPath(t.PathPrefix)
+	}
+	return nil
+}
+
+// CreateAPIToken stores the token and returns the full token string, which can not be recovered later
+func CreateAPIToken(t *model.APIToken) (string, error) {
+	if err := checkScopes(t); err != nil {
+		return "", err
+	}
+	key, err := random.SecureString(20)
+	if err != nil {
+		return "", err
+	}
+	secret, err := random.SecureString(32)
+	if err != nil {
+		return "", err
+	}
+	t.Kind = model.KindAPIToken
+	t.Key = strings.ToLower(key)
+	t.Hash = hashAPITokenSecret(secret)
+	if err := db.CreateAPIToken(t); err != nil {
+		return "", err
+	}
+	return APITokenPrefix + t.Key + "-" + secret, nil
+}
+
+func GetAPITokens(userId uint, kind string) ([]model.APIToken, error) {
+	return db.GetAPITokens(userId, kind)
+}
+
+func GetAPITokenById(id uint) (*model.APIToken, error) {
+	return db.GetAPITokenById(id)
+}
+
+func DeleteAPITokenById(id uint) error {
+	return db.DeleteAPITokenById(id)
+}
+
+// GetAPITokenByKey returns the token with the public key, without verifying the secret
+func GetAPITokenByKey(key string) (*model.APIToken, error) {
+	t, err := db.GetAPITokenByKey(key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.APITokenInvalid
+		}
+		return nil, err
+	}
+	if t.Expired() {
+		return nil, errs.APITokenExpired
+	}
+	return t, nil
+}
+
+// AuthAPIToken verifies the full token string and returns the user restricted to the token
+func AuthAPIToken(token string) (*model.User, *model.APIToken, error) {
+	key, secret, ok := strings.Cut(strings.TrimPrefix(token, APITokenPrefix), "-")
+	if !ok || !IsAPIToken(token) {
+		return nil, nil, errs.APITokenInvalid
+	}
+	t, err := GetAPITokenByKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.Kind != model.KindAPIToken {
+		return nil, nil, errs.APITokenInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hashAPITokenSecret(secret))) != 1 {
+		return nil, nil, errs.APITokenInvalid
+	}
+	user, err := GetAPITokenUser(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, t, nil
+}
+
+// GetAPITokenUser returns a copy of the owner of the token whose base path is narrowed to the path prefix
+func GetAPITokenUser(t *model.APIToken) (*model.User, error) {
+	owner, err := GetUserById(t.UserID)
+	if err != nil {
+		return nil, errs.APITokenInvalid
+	}
+	if owner.Disabled {
+		return nil, errors.New("current user is disabled")
+	}
+	// record the usage at most once a minute
+	if time.Since(t.LastUsed) > time.Minute {
+		if err := db.UpdateAPITokenLastUsed(t.ID, time.Now()); err != nil {
+			utils.Log.Warnf("failed update last used of api token %d: %+v", t.ID, err)
+		}
+	}
+	user := *owner
+	if t.PathPrefix != "" && t.PathPrefix != "/" {
+		user.BasePath = path.Join(user.BasePath, t.PathPrefix)
+	}
+	return &user, nil
+}
+
+// APITokenS3Secret derives the s3 secret access key of the token, the server never stores it
+func APITokenS3Secret(t *model.APIToken) string {
+	mac := hmac.New(sha256.New, []byte(conf.Conf.JwtSecret))
+	mac.Write([]byte(t.Key + t.Hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
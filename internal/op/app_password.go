@@ -0,0 +1,89 @@
+package op
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+// normalizeAppPassword makes the app password insensitive to case and separators, as it may be typed by hand
+func normalizeAppPassword(password string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(password))
+}
+
+// IsAppPassword reports whether the password is in the form of app passwords, e.g. abcd-efgh-ijkl-mnop
+func IsAppPassword(password string) bool {
+	p := normalizeAppPassword(password)
+	if len(p) != 16 {
+		return false
+	}
+	_, err := base32.StdEncoding.DecodeString(strings.ToUpper(p))
+	return err == nil
+}
+
+// CreateAppPassword stores the app password and returns it, it can not be recovered later
+func CreateAppPassword(t *model.APIToken) (string, error) {
+	if err := checkScopes(t); err != nil {
+		return "", err
+	}
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s != model.ScopeFsRead && s != model.ScopeFsWrite {
+			return "", errors.Errorf("app passwords can not be granted the scope: %s", s)
+		}
+	}
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	p := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	password := p[:4] + "-" + p[4:8] + "-" + p[8:12] + "-" + p[12:]
+	key, err := random.SecureString(20)
+	if err != nil {
+		return "", err
+	}
+	t.Kind = model.KindAppPassword
+	t.Key = strings.ToLower(key)
+	t.Hash = hashAPITokenSecret(p)
+	if err := db.CreateAPIToken(t); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// AuthAppPassword verifies the app password of the user and returns the user restricted to it
+func AuthAppPassword(username, password string) (*model.User, *model.APIToken, error) {
+	if !IsAppPassword(password) {
+		return nil, nil, errs.APITokenInvalid
+	}
+	owner, err := GetUserByName(username)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := db.GetAPITokens(owner.ID, model.KindAppPassword)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash := hashAPITokenSecret(normalizeAppPassword(password))
+	for i := range tokens {
+		t := &tokens[i]
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) != 1 {
+			continue
+		}
+		if t.Expired() {
+			return nil, nil, errs.APITokenExpired
+		}
+		user, err := GetAPITokenUser(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		return user, t, nil
+	}
+	return nil, nil, errs.APITokenInvalid
+}
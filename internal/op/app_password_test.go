@@ -0,0 +1,44 @@
+package op_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestAppPassword(t *testing.T) {
+	user := &model.User{Username: "app_password_user", BasePath: "/data"}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	token := &model.APIToken{UserID: user.ID, Name: "laptop", Scopes: model.ScopeFsRead, PathPrefix: "docs"}
+	password, err := op.CreateAppPassword(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !op.IsAppPassword(password) {
+		t.Fatalf("unexpected app password %q", password)
+	}
+	for _, p := range []string{password, strings.ToUpper(password), strings.ReplaceAll(password, "-", "")} {
+		u, got, err := op.AuthAppPassword(user.Username, p)
+		if err != nil {
+			t.Fatalf("auth %q: %v", p, err)
+		}
+		if got.ID != token.ID || u.BasePath != "/data/docs" {
+			t.Errorf("got token %d, base path %s", got.ID, u.BasePath)
+		}
+	}
+	if _, _, err := op.AuthAppPassword("admin", password); err == nil {
+		t.Error("app password of another user is accepted")
+	}
+	// the app password must not be usable as an api token
+	forged := op.APITokenPrefix + token.Key + "-" + strings.ReplaceAll(password, "-", "")
+	if _, _, err := op.AuthAPIToken(forged); err == nil {
+		t.Error("app password is accepted as an api token")
+	}
+	if _, err := op.CreateAppPassword(&model.APIToken{UserID: user.ID, Name: "x", Scopes: model.ScopeAdmin}); err == nil {
+		t.Error("app password is granted the admin scope")
+	}
+}
@@ -0,0 +1,215 @@
+package op
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// a storage is ejected from its balance group after the consecutive failures
+	balanceMaxFailures = 3
+	// the ejection is doubled each time the storage fails again after re-admission
+	balanceEjectTime    = 30 * time.Second
+	balanceMaxEjectTime = 10 * time.Minute
+)
+
+type storageHealth struct {
+	sync.Mutex
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+	latency      time.Duration
+	lastError    string
+}
+
+// StorageHealth is the health of a storage tracked by the results of List and Link
+type StorageHealth struct {
+	Failures     int       `json:"failures"` // consecutive failures
+	Latency      int64     `json:"latency"`  // moving average in milliseconds
+	Ejected      bool      `json:"ejected"`
+	EjectedUntil time.Time `json:"ejected_until"`
+	LastError    string    `json:"last_error"`
+}
+
+var healthMap generic_sync.MapOf[string, *storageHealth]
+
+// GetStorageHealth returns the health of the storage of the mount path
+func GetStorageHealth(mountPath string) StorageHealth {
+	h, ok := healthMap.Load(mountPath)
+	if !ok {
+		return StorageHealth{}
+	}
+	h.Lock()
+	defer h.Unlock()
+	return StorageHealth{
+		Failures:     h.failures,
+		Latency:      h.latency.Milliseconds(),
+		Ejected:      time.Now().Before(h.ejectedUntil),
+		EjectedUntil: h.ejectedUntil,
+		LastError:    h.lastError,
+	}
+}
+
+// recordHealth records the result of a request to the storage, errors which are not the fault
+// of the storage, such as not found or canceled by the client, are ignored
+func recordHealth(ctx context.Context, storage driver.Driver, start time.Time, err error) {
+	if err != nil && (ctx.Err() != nil || errs.IsObjectNotFound(err)) {
+		return
+	}
+	mountPath := storage.GetStorage().MountPath
+	h, _ := healthMap.LoadOrStore(mountPath, &storageHealth{})
+	h.Lock()
+	defer h.Unlock()
+	if err == nil {
+		d := time.Since(start)
+		if h.latency == 0 {
+			h.latency = d
+		} else {
+			h.latency = (h.latency*7 + d) / 8
+		}
+		h.failures, h.ejections, h.ejectedUntil = 0, 0, time.Time{}
+		return
+	}
+	h.failures++
+	h.lastError = err.Error()
+	now := time.Now()
+	// a re-admitted storage is ejected again on its first failure
+	if h.failures >= balanceMaxFailures && !now.Before(h.ejectedUntil) {
+		d := min(balanceEjectTime<<min(h.ejections, 10), balanceMaxEjectTime)
+		h.ejections++
+		h.ejectedUntil = now.Add(d)
+		log.Warnf("storage %s is ejected for %s after %d failures: %s", mountPath, d, h.failures, h.lastError)
+	}
+}
+
+func healthy(storage driver.Driver) bool {
+	if storage.GetStorage().Status != WORK {
+		return false
+	}
+	h, ok := healthMap.Load(storage.GetStorage().MountPath)
+	if !ok {
+		return true
+	}
+	h.Lock()
+	defer h.Unlock()
+	return !time.Now().Before(h.ejectedUntil)
+}
+
+func latency(storage driver.Driver) time.Duration {
+	h, ok := healthMap.Load(storage.GetStorage().MountPath)
+	if !ok {
+		return 0
+	}
+	h.Lock()
+	defer h.Unlock()
+	return h.latency
+}
+
+func weight(storage driver.Driver) int {
+	return max(storage.GetStorage().BalanceWeight, 1)
+}
+
+type balancer struct {
+	sync.Mutex
+	next    int
+	current map[string]int // of the smooth weighted round-robin
+}
+
+var balancerMap generic_sync.MapOf[string, *balancer]
+
+// pick returns the index of the storage to use by the strategy
+func (b *balancer) pick(strategy string, storages []driver.Driver, key string) int {
+	b.Lock()
+	defer b.Unlock()
+	switch strategy {
+	case model.BalanceWeighted:
+		if b.current == nil {
+			b.current = make(map[string]int)
+		}
+		total, best := 0, 0
+		for i, s := range storages {
+			w := weight(s)
+			total += w
+			b.current[s.GetStorage().MountPath] += w
+			if b.current[s.GetStorage().MountPath] > b.current[storages[best].GetStorage().MountPath] {
+				best = i
+			}
+		}
+		b.current[storages[best].GetStorage().MountPath] -= total
+		return best
+	case model.BalanceLeastLatency:
+		// storages not measured yet have no latency, so they are tried first
+		best := 0
+		for i, s := range storages {
+			if latency(s) < latency(storages[best]) {
+				best = i
+			}
+		}
+		return best
+	case model.BalanceSticky:
+		if key == "" {
+			break
+		}
+		// rendezvous hashing keeps the other clients in place when a storage is ejected
+		var best int
+		var bestScore uint64
+		for i, s := range storages {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(key + "|" + s.GetStorage().MountPath))
+			if score := h.Sum64(); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		return best
+	}
+	b.next = (b.next + 1) % len(storages)
+	return b.next
+}
+
+// GetBalancedStorages returns the storages of the longest matched mount path in the order to try,
+// the storage selected by the strategy first, then the other healthy ones, then the unhealthy ones.
+// key is used by the sticky strategy, e.g. the ip of the client
+func GetBalancedStorages(path, key string) []driver.Driver {
+	path = utils.FixAndCleanPath(path)
+	storages := getStoragesByPath(path)
+	if len(storages) <= 1 {
+		return storages
+	}
+	var candidates, unhealthy []driver.Driver
+	for _, s := range storages {
+		if healthy(s) {
+			candidates = append(candidates, s)
+		} else {
+			unhealthy = append(unhealthy, s)
+		}
+	}
+	if len(candidates) == 0 {
+		// better to try than to fail directly
+		candidates, unhealthy = unhealthy, nil
+	}
+	virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
+	b, _ := balancerMap.LoadOrStore(virtualPath, &balancer{})
+	i := b.pick(storages[0].GetStorage().BalanceStrategy, candidates, key)
+	res := make([]driver.Driver, 0, len(storages))
+	res = append(res, candidates[i:]...)
+	res = append(res, candidates[:i]...)
+	return append(res, unhealthy...)
+}
+
+// GetBalancedStorage get storage by path
+func GetBalancedStorage(path string) driver.Driver {
+	storages := GetBalancedStorages(path, "")
+	if len(storages) == 0 {
+		return nil
+	}
+	return storages[0]
+}
@@ -0,0 +1,94 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type balanceDriver struct {
+	driver.Driver
+	storage model.Storage
+}
+
+func (d *balanceDriver) GetStorage() *model.Storage {
+	return &d.storage
+}
+
+func setupBalance(t *testing.T, strategy string, weights ...int) []driver.Driver {
+	var res []driver.Driver
+	for i, w := range weights {
+		mountPath := "/balance_" + strategy
+		if i > 0 {
+			mountPath += ".balance" + string(rune('0'+i))
+		}
+		d := &balanceDriver{storage: model.Storage{
+			MountPath: mountPath,
+			Status:    WORK,
+			Balance:   model.Balance{BalanceStrategy: strategy, BalanceWeight: w},
+		}}
+		storagesMap.Store(mountPath, d)
+		res = append(res, d)
+	}
+	t.Cleanup(func() {
+		for _, d := range res {
+			storagesMap.Delete(d.GetStorage().MountPath)
+			healthMap.Delete(d.GetStorage().MountPath)
+		}
+	})
+	return res
+}
+
+func TestBalanceWeighted(t *testing.T) {
+	storages := setupBalance(t, model.BalanceWeighted, 3, 1)
+	count := map[driver.Driver]int{}
+	for i := 0; i < 8; i++ {
+		count[GetBalancedStorage("/balance_weighted/file")]++
+	}
+	if count[storages[0]] != 6 || count[storages[1]] != 2 {
+		t.Errorf("expected 6 and 2, got %d and %d", count[storages[0]], count[storages[1]])
+	}
+}
+
+func TestBalanceSticky(t *testing.T) {
+	setupBalance(t, model.BalanceSticky, 1, 1, 1)
+	first := GetBalancedStorages("/balance_sticky", "1.2.3.4")[0]
+	for i := 0; i < 5; i++ {
+		if s := GetBalancedStorages("/balance_sticky", "1.2.3.4")[0]; s != first {
+			t.Fatalf("expected %s, got %s", first.GetStorage().MountPath, s.GetStorage().MountPath)
+		}
+	}
+}
+
+func TestBalanceEjection(t *testing.T) {
+	storages := setupBalance(t, model.BalanceRoundRobin, 1, 1)
+	bad := storages[0]
+	for i := 0; i < balanceMaxFailures; i++ {
+		recordHealth(context.Background(), bad, time.Now(), errors.New("token expired"))
+	}
+	if !GetStorageHealth(bad.GetStorage().MountPath).Ejected {
+		t.Fatal("expected the storage to be ejected")
+	}
+	for i := 0; i < 4; i++ {
+		got := GetBalancedStorages("/balance_round_robin", "")
+		if len(got) != 2 || got[0] != storages[1] || got[1] != bad {
+			t.Fatalf("expected the ejected storage to be the last candidate")
+		}
+	}
+	// re-admitted after the ejection, and recovered by a success
+	h, _ := healthMap.Load(bad.GetStorage().MountPath)
+	h.ejectedUntil = time.Now()
+	recordHealth(context.Background(), bad, time.Now(), nil)
+	if health := GetStorageHealth(bad.GetStorage().MountPath); health.Ejected || health.Failures != 0 {
+		t.Errorf("expected the storage to be recovered, got %+v", health)
+	}
+	// a storage which failed to init is not selected
+	storages[1].GetStorage().Status = "failed"
+	if got := GetBalancedStorage("/balance_round_robin"); got != bad {
+		t.Errorf("expected %s, got %s", bad.GetStorage().MountPath, got.GetStorage().MountPath)
+	}
+}
@@ -138,6 +138,21 @@ func getMainItems(config driver.Config) []driver.Item {
 		Type:     conf.TypeBool,
 		Default:  "false",
 		Required: true,
+	}, driver.Item{
+		Name:     "read_only",
+		Type:     conf.TypeBool,
+		Default:  "false",
+		Required: true,
+	}, driver.Item{
+		Name:     "hidden",
+		Type:     conf.TypeBool,
+		Default:  "false",
+		Required: true,
+		Help:     "not listed in the parent, but still accessible by the path",
+	}, driver.Item{
+		Name: "visible_to",
+		Type: conf.TypeText,
+		Help: "usernames, group:<group> or role:<general|guest|admin> separated by commas or new lines, everyone if empty",
 	})
 	return items
 }
@@ -2,6 +2,7 @@ package op
 
 import (
 	"context"
+	"io"
 	stdpath "path"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
@@ -122,7 +125,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		start := time.Now()
 		files, err := storage.List(ctx, dir, args)
+		recordHealth(ctx, storage, start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -255,7 +260,9 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		start := time.Now()
 		link, err := storage.Link(ctx, file, args)
+		recordHealth(ctx, storage, start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
@@ -277,6 +284,32 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 	return link, file, err
 }
 
+// OpenRange opens a range of the file of size in the storage by its link, the closers of the link are added to closers.
+// it is used by the drivers storing the files in another storage, such as chunker and compress
+func OpenRange(ctx context.Context, storage driver.Driver, path string, size int64, r http_range.Range,
+	args model.LinkArgs, closers *utils.Closers) (io.ReadCloser, error) {
+	link, _, err := Link(ctx, storage, path, args)
+	if err != nil {
+		return nil, err
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil && len(link.URL) > 0 {
+		rrc, err = stream.GetRangeReadCloserFromLink(size, &model.Link{URL: link.URL, Header: link.Header})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rrc != nil {
+		rc, err := rrc.RangeRead(ctx, r)
+		closers.AddClosers(rrc.GetClosers())
+		return rc, err
+	}
+	if link.MFile != nil {
+		return utils.NewReadCloser(io.NewSectionReader(link.MFile, r.Start, r.Length), link.MFile.Close), nil
+	}
+	return nil, errs.NotSupport
+}
+
 // Other api
 func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (interface{}, error) {
 	obj, err := GetUnwrap(ctx, storage, args.Path)
@@ -300,6 +333,9 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	path = utils.FixAndCleanPath(path)
 	key := Key(storage, path)
 	_, err, _ := mkdirG.Do(key, func() (interface{}, error) {
@@ -355,6 +391,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	srcPath = utils.FixAndCleanPath(srcPath)
 	dstDirPath = utils.FixAndCleanPath(dstDirPath)
 	srcRawObj, err := Get(ctx, storage, srcPath)
@@ -398,6 +437,9 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	srcPath = utils.FixAndCleanPath(srcPath)
 	srcRawObj, err := Get(ctx, storage, srcPath)
 	if err != nil {
@@ -433,6 +475,9 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	srcPath = utils.FixAndCleanPath(srcPath)
 	dstDirPath = utils.FixAndCleanPath(dstDirPath)
 	srcObj, err := GetUnwrap(ctx, storage, srcPath)
@@ -466,10 +511,95 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	return errors.WithStack(err)
 }
 
+// checkWritable rejects the writes to the read-only storages
+func checkWritable(storages ...driver.Driver) error {
+	for _, storage := range storages {
+		if storage.GetStorage().ReadOnly {
+			return errors.WithStack(errs.StorageReadOnly)
+		}
+	}
+	return nil
+}
+
+func checkStatus(storages ...driver.Driver) error {
+	for _, storage := range storages {
+		if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+			return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+		}
+	}
+	return nil
+}
+
+// CrossStorageCopy copies the object to another storage on the server side if the storages share the account,
+// errs.NotSupport is returned if not possible, then the object should be copied by transferring the data
+func CrossStorageCopy(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string, lazyCache ...bool) error {
+	s, ok := srcStorage.(driver.CrossStorageCopier)
+	if !ok || !s.SameAccount(dstStorage) {
+		return errs.NotSupport
+	}
+	if err := checkStatus(srcStorage, dstStorage); err != nil {
+		return err
+	}
+	if err := checkWritable(dstStorage); err != nil {
+		return err
+	}
+	srcPath = utils.FixAndCleanPath(srcPath)
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	srcObj, err := GetUnwrap(ctx, srcStorage, srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src object")
+	}
+	dstDir, err := GetUnwrap(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst dir")
+	}
+	err = s.CopyTo(ctx, srcObj, dstStorage, dstDir)
+	if err == nil && !utils.IsBool(lazyCache...) {
+		ClearCache(dstStorage, dstDirPath)
+	}
+	return errors.WithStack(err)
+}
+
+// CrossStorageMove moves the object to another storage on the server side if the storages share the account,
+// errs.NotSupport is returned if not possible
+func CrossStorageMove(ctx context.Context, srcStorage driver.Driver, srcPath string, dstStorage driver.Driver, dstDirPath string, lazyCache ...bool) error {
+	s, ok := srcStorage.(driver.CrossStorageMover)
+	if !ok || !s.SameAccount(dstStorage) {
+		return errs.NotSupport
+	}
+	if err := checkStatus(srcStorage, dstStorage); err != nil {
+		return err
+	}
+	if err := checkWritable(srcStorage, dstStorage); err != nil {
+		return err
+	}
+	srcPath = utils.FixAndCleanPath(srcPath)
+	dstDirPath = utils.FixAndCleanPath(dstDirPath)
+	srcRawObj, err := Get(ctx, srcStorage, srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src object")
+	}
+	dstDir, err := GetUnwrap(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst dir")
+	}
+	err = s.MoveTo(ctx, model.UnwrapObj(srcRawObj), dstStorage, dstDir)
+	if err == nil {
+		delCacheObj(srcStorage, stdpath.Dir(srcPath), srcRawObj)
+		if !utils.IsBool(lazyCache...) {
+			ClearCache(dstStorage, dstDirPath)
+		}
+	}
+	return errors.WithStack(err)
+}
+
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	if utils.PathEqual(path, "/") {
 		return errors.New("delete root folder is not allowed, please goto the manage page to delete the storage instead")
 	}
@@ -505,6 +635,9 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkWritable(storage); err != nil {
+		return err
+	}
 	defer func() {
 		if err := file.Close(); err != nil {
 			log.Errorf("failed to close file streamer, %v", err)
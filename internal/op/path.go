@@ -12,9 +12,20 @@ import (
 // GetStorageAndActualPath Get the corresponding storage and actual path
 // for path: remove the mount path prefix and join the actual root folder if exists
 func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath string, err error) {
+	storages, actualPath, err := GetStoragesAndActualPath(rawPath, "")
+	if err != nil {
+		return
+	}
+	storage = storages[0]
+	return
+}
+
+// GetStoragesAndActualPath is like GetStorageAndActualPath, but returns all the storages of a balance group
+// in the order to try, see GetBalancedStorages
+func GetStoragesAndActualPath(rawPath, key string) (storages []driver.Driver, actualPath string, err error) {
 	rawPath = utils.FixAndCleanPath(rawPath)
-	storage = GetBalancedStorage(rawPath)
-	if storage == nil {
+	storages = GetBalancedStorages(rawPath, key)
+	if len(storages) == 0 {
 		if rawPath == "/" {
 			err = errs.NewErr(errs.StorageNotFound, "please add a storage first")
 			return
@@ -22,8 +33,8 @@ func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath
 		err = errs.NewErr(errs.StorageNotFound, "rawPath: %s", rawPath)
 		return
 	}
-	log.Debugln("use storage: ", storage.GetStorage().MountPath)
-	mountPath := utils.GetActualMountPath(storage.GetStorage().MountPath)
+	log.Debugln("use storage: ", storages[0].GetStorage().MountPath)
+	mountPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
 	actualPath = utils.FixAndCleanPath(strings.TrimPrefix(rawPath, mountPath))
 	return
 }
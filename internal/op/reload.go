@@ -0,0 +1,39 @@
+package op
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ReloadConfig drops the caches and reloads all storages,
+// used after the config in the database is replaced as a whole
+func ReloadConfig(ctx context.Context) error {
+	settingCacheUpdate()
+	userCache.Clear()
+	adminUser, guestUser = nil, nil
+	sessionCache.Clear()
+	metaCache.Clear()
+	listCache.Clear()
+	linkCache.Clear()
+	for _, storageDriver := range storagesMap.Values() {
+		mountPath := storageDriver.GetStorage().MountPath
+		if err := storageDriver.Drop(ctx); err != nil {
+			utils.Log.Warnf("failed drop storage [%s]: %+v", mountPath, err)
+		}
+		storagesMap.Delete(mountPath)
+		go callStorageHooks("del", storageDriver)
+	}
+	storages, err := db.GetEnabledStorages()
+	if err != nil {
+		return errors.WithMessage(err, "failed get enabled storages")
+	}
+	for i := range storages {
+		if err := LoadStorage(ctx, storages[i]); err != nil {
+			utils.Log.Errorf("failed load storage [%s]: %+v", storages[i].MountPath, err)
+		}
+	}
+	return nil
+}
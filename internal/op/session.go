@@ -0,0 +1,197 @@
+package op
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var sessionCache = cache.NewMemCache(cache.WithShards[*model.Session](16))
+
+func sessionLifetime() time.Duration {
+	return time.Duration(conf.Conf.TokenExpiresIn) * time.Hour
+}
+
+func hashRefreshSecret(secret string) string {
+	return utils.HashData(utils.SHA256, []byte(secret))
+}
+
+// newRefreshToken sets a new refresh secret to the session and returns the refresh token
+func newRefreshToken(s *model.Session) (string, error) {
+	secret, err := random.SecureString(32)
+	if err != nil {
+		return "", err
+	}
+	s.RefreshHash = hashRefreshSecret(secret)
+	return s.SessionID + "." + secret, nil
+}
+
+// CreateSession records a new login of the user with s, whose device, ip and sso fields are set by the caller,
+// the refresh token of the session is returned
+func CreateSession(user *model.User, s *model.Session) (string, error) {
+	id, err := random.SecureString(32)
+	if err != nil {
+		return "", err
+	}
+	s.SessionID = id
+	s.UserID = user.ID
+	s.PwdTS = user.PwdTS
+	s.Expire = time.Now().Add(sessionLifetime()).Unix()
+	s.LastSeen = time.Now()
+	refresh, err := newRefreshToken(s)
+	if err != nil {
+		return "", err
+	}
+	if err := db.CreateSession(s); err != nil {
+		return "", err
+	}
+	return refresh, nil
+}
+
+// GetSession returns the active session
+func GetSession(sessionId string) (*model.Session, error) {
+	s, ok := sessionCache.Get(sessionId)
+	if !ok {
+		var err error
+		s, err = db.GetSessionBySessionId(sessionId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errs.SessionNotFound
+			}
+			return nil, err
+		}
+		sessionCache.Set(sessionId, s, cache.WithEx[*model.Session](time.Minute*5))
+	}
+	if s.Expired() {
+		return nil, errs.SessionNotFound
+	}
+	return s, nil
+}
+
+// TouchSession records the activity of the session, at most once a minute
+func TouchSession(s *model.Session, ip string) {
+	now := time.Now()
+	if now.Sub(s.LastSeen) < time.Minute && s.IP == ip {
+		return
+	}
+	ns := *s
+	ns.LastSeen, ns.IP = now, ip
+	sessionCache.Set(ns.SessionID, &ns, cache.WithEx[*model.Session](time.Minute*5))
+	if err := db.UpdateSessionLastSeen(ns.ID, ip, now); err != nil {
+		utils.Log.Warnf("failed update last seen of session %s: %+v", ns.SessionID, err)
+	}
+}
+
+// RefreshSession rotates the refresh token and extends the session,
+// the user of the session is returned to issue a new access token.
+// A rotated refresh token presented again revokes the session, as the token must have been stolen
+func RefreshSession(refreshToken string) (*model.Session, *model.User, string, error) {
+	sessionId, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return nil, nil, "", errs.RefreshInvalid
+	}
+	// not the cached one, the refresh hash may have been rotated by another instance
+	s, err := db.GetSessionBySessionId(sessionId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, "", errs.SessionNotFound
+		}
+		return nil, nil, "", err
+	}
+	if s.Expired() {
+		return nil, nil, "", errs.SessionNotFound
+	}
+	hash := hashRefreshSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(s.RefreshHash), []byte(hash)) != 1 {
+		if s.PrevRefreshHash != "" && subtle.ConstantTimeCompare([]byte(s.PrevRefreshHash), []byte(hash)) == 1 {
+			revokeReusedSession(s)
+		}
+		return nil, nil, "", errs.RefreshInvalid
+	}
+	user, err := GetUserById(s.UserID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if user.Disabled || user.PwdTS != s.PwdTS {
+		_ = DeleteSession(s.SessionID)
+		return nil, nil, "", errs.SessionNotFound
+	}
+	ns := *s
+	ns.PrevRefreshHash = s.RefreshHash
+	refresh, err := newRefreshToken(&ns)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	ns.Expire = time.Now().Add(sessionLifetime()).Unix()
+	ns.LastSeen = time.Now()
+	rotated, err := db.RotateSession(&ns, s.RefreshHash)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if !rotated {
+		// the same refresh token is used by two requests at the same time
+		revokeReusedSession(s)
+		return nil, nil, "", errs.RefreshInvalid
+	}
+	sessionCache.Del(sessionId)
+	return &ns, user, refresh, nil
+}
+
+func revokeReusedSession(s *model.Session) {
+	utils.Log.Warnf("reused refresh token of session %s of user %d, revoking it", s.SessionID, s.UserID)
+	if err := DeleteSession(s.SessionID); err != nil {
+		utils.Log.Errorf("failed revoke session %s: %+v", s.SessionID, err)
+	}
+}
+
+func GetSessions(userId uint) ([]model.Session, error) {
+	return db.GetSessionsByUserId(userId)
+}
+
+// DeleteExpiredSessions removes the expired sessions, called periodically
+func DeleteExpiredSessions() {
+	if err := db.DeleteExpiredSessions(); err != nil {
+		utils.Log.Warnf("failed delete expired sessions: %+v", err)
+	}
+}
+
+func DeleteSession(sessionId string) error {
+	sessionCache.Del(sessionId)
+	return db.DeleteSessionBySessionId(sessionId)
+}
+
+// DeleteSessionsByUserId logs out the user everywhere
+func DeleteSessionsByUserId(userId uint) error {
+	sessions, err := db.GetSessionsByUserId(userId)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		sessionCache.Del(s.SessionID)
+	}
+	return db.DeleteSessionsByUserId(userId)
+}
+
+// DeleteSSOSessions logs out the sessions of the oidc provider by the sid, or by the subject if sid is empty
+func DeleteSSOSessions(provider, subject, sid string) error {
+	sessions, err := db.GetSSOSessions(provider, subject, sid)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if err := DeleteSession(s.SessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package op
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateSignedLink(l *model.SignedLink) error {
+	l.Key = random.String(16)
+	l.Path = utils.FixAndCleanPath(l.Path)
+	l.Uses = 0
+	return db.CreateSignedLink(l)
+}
+
+func GetSignedLinks(userId uint, pageIndex, pageSize int) ([]model.SignedLink, int64, error) {
+	return db.GetSignedLinks(userId, pageIndex, pageSize)
+}
+
+func GetSignedLinkById(id uint) (*model.SignedLink, error) {
+	return db.GetSignedLinkById(id)
+}
+
+func DeleteSignedLinkById(id uint) error {
+	return db.DeleteSignedLinkById(id)
+}
+
+// signedLinkClients records the clients which have spent a use of a signed link,
+// so the following range requests of a player or a downloader are not counted again
+var signedLinkClients = cache.NewMemCache(cache.WithShards[struct{}](16))
+
+// signedLinkSession is how long a spent use lasts for a client after its last request
+const signedLinkSession = time.Hour
+
+// CheckSignedLink checks the restrictions of the signed link for a request of path,
+// a read of the file spends a use on the first request of each client, the owner of the link is returned
+func CheckSignedLink(key, path, ip, referer, client string, read bool) (*model.User, error) {
+	l, err := db.GetSignedLinkByKey(key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.SignedLinkNotFound
+		}
+		return nil, err
+	}
+	if l.Path != path {
+		return nil, errs.SignedLinkNotFound
+	}
+	// links of a deleted or disabled user stop working
+	u, err := GetUserById(l.UserID)
+	if err != nil || u.Disabled {
+		return nil, errs.SignedLinkNotFound
+	}
+	if l.Expired() {
+		return nil, errs.SignedLinkExpired
+	}
+	if !l.AllowIP(ip) {
+		return nil, errs.SignedLinkIPDenied
+	}
+	if !l.AllowReferer(referer) {
+		return nil, errs.SignedLinkRefererDenied
+	}
+	if !read {
+		if l.Exhausted() {
+			return nil, errs.SignedLinkExhausted
+		}
+		return u, nil
+	}
+	session := fmt.Sprintf("%d|%s", l.ID, client)
+	if _, ok := signedLinkClients.Get(session); ok {
+		signedLinkClients.Set(session, struct{}{}, cache.WithEx[struct{}](signedLinkSession))
+		return u, nil
+	}
+	ok, err := db.UseSignedLink(l.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.SignedLinkExhausted
+	}
+	signedLinkClients.Set(session, struct{}{}, cache.WithEx[struct{}](signedLinkSession))
+	return u, nil
+}
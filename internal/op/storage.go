@@ -94,6 +94,7 @@ func getCurrentGoroutineStack() string {
 // initStorage initialize the driver and store to storagesMap
 func initStorage(ctx context.Context, storage model.Storage, storageDriver driver.Driver) (err error) {
 	storageDriver.SetStorage(storage)
+	healthMap.Delete(storage.MountPath)
 	driverStorage := storageDriver.GetStorage()
 	defer func() {
 		if err := recover(); err != nil {
@@ -140,6 +141,28 @@ func EnableStorage(ctx context.Context, id uint) error {
 	return nil
 }
 
+// ReinitStorage drops the enabled storage and initializes it again with the storage in the database,
+// used to recover a broken storage, e.g. whose token is expired
+func ReinitStorage(ctx context.Context, id uint) error {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Disabled {
+		return errors.Errorf("this storage have disabled")
+	}
+	storageDriver, err := GetStorageByMountPath(storage.MountPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage driver")
+	}
+	if err := storageDriver.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage [%s]: %+v", storage.MountPath, err)
+	}
+	err = initStorage(ctx, *storage, storageDriver)
+	go callStorageHooks("update", storageDriver)
+	return err
+}
+
 func DisableStorage(ctx context.Context, id uint) error {
 	storage, err := db.GetStorageById(id)
 	if err != nil {
@@ -229,6 +252,9 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
 	}
+	if err := db.DeleteStorageChecksByStorageId(id); err != nil {
+		return errors.WithMessage(err, "failed delete storage checks in database")
+	}
 	return nil
 }
 
@@ -288,6 +314,35 @@ func getStoragesByPath(path string) []driver.Driver {
 // for example, there are: /a/b,/a/c,/a/d/e,/a/b.balance1,/av
 // GetStorageVirtualFilesByPath(/a) => b,c,d
 func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
+	return getStorageVirtualFilesByPath(prefix, nil)
+}
+
+// GetVisibleStorageVirtualFilesByPath is like GetStorageVirtualFilesByPath, but excludes the storages the user can't see,
+// and the hidden ones if listing unless the user can see hides. a virtual folder is kept if any of its storages is kept
+func GetVisibleStorageVirtualFilesByPath(prefix string, user *model.User, listing bool) []model.Obj {
+	return getStorageVirtualFilesByPath(prefix, func(storage *model.Storage) bool {
+		if listing && storage.Hidden && user != nil && !user.CanSeeHides() {
+			return false
+		}
+		return CanSeeStorage(user, storage)
+	})
+}
+
+// CanSeeStorage reports whether the user can see and access the storage, see model.MountOptions
+func CanSeeStorage(user *model.User, storage *model.Storage) bool {
+	return storage.IsVisibleTo(user, func() []string {
+		groups, err := db.GetUserScimGroups(user.ID)
+		if err != nil {
+			log.Errorf("failed get groups of user [%s]: %+v", user.Username, err)
+			return nil
+		}
+		return utils.MustSliceConvert(groups, func(g model.ScimGroup) string {
+			return g.DisplayName
+		})
+	})
+}
+
+func getStorageVirtualFilesByPath(prefix string, filter func(storage *model.Storage) bool) []model.Obj {
 	files := make([]model.Obj, 0)
 	storages := storagesMap.Values()
 	sort.Slice(storages, func(i, j int) bool {
@@ -305,6 +360,9 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 		if len(prefix) >= len(mountPath) || !utils.IsSubPath(prefix, mountPath) {
 			continue
 		}
+		if filter != nil && !filter(v.GetStorage()) {
+			continue
+		}
 		name := strings.SplitN(strings.TrimPrefix(mountPath[len(prefix):], "/"), "/", 2)[0]
 		if set.Add(name) {
 			files = append(files, &model.Object{
@@ -317,24 +375,3 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 	}
 	return files
 }
-
-var balanceMap generic_sync.MapOf[string, int]
-
-// GetBalancedStorage get storage by path
-func GetBalancedStorage(path string) driver.Driver {
-	path = utils.FixAndCleanPath(path)
-	storages := getStoragesByPath(path)
-	storageNum := len(storages)
-	switch storageNum {
-	case 0:
-		return nil
-	case 1:
-		return storages[0]
-	default:
-		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
-		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
-		balanceMap.Store(virtualPath, i)
-		return storages[i]
-	}
-}
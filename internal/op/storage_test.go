@@ -2,10 +2,14 @@ package op_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -88,3 +92,63 @@ func setupStorages(t *testing.T) {
 		}
 	}
 }
+
+func TestMountOptions(t *testing.T) {
+	ctx := context.Background()
+	var storages = []model.Storage{
+		{Driver: "Local", MountPath: "/m/ro", MountOptions: model.MountOptions{ReadOnly: true}},
+		{Driver: "Local", MountPath: "/m/hidden", MountOptions: model.MountOptions{Hidden: true}},
+		{Driver: "Local", MountPath: "/m/private", MountOptions: model.MountOptions{VisibleTo: "alice\ngroup:Ops, role:guest"}},
+	}
+	for _, storage := range storages {
+		storage.Addition = fmt.Sprintf(`{"root_folder_path":%q}`, t.TempDir())
+		if _, err := op.CreateStorage(ctx, storage); err != nil {
+			t.Fatalf("failed create storage: %+v", err)
+		}
+	}
+	ro, err := op.GetStorageByMountPath("/m/ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := op.MakeDir(ctx, ro, "/dir"); !errors.Is(err, errs.StorageReadOnly) {
+		t.Errorf("expected read-only, got: %v", err)
+	}
+
+	bob := &model.User{Username: "bob", Role: model.GENERAL}
+	carol := &model.User{Username: "carol", Role: model.GENERAL}
+	for _, u := range []*model.User{bob, carol} {
+		if err := db.CreateUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	group := &model.ScimGroup{DisplayName: "ops"}
+	if err := db.CreateScimGroup(group); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetScimGroupMembers(group.ID, []uint{bob.ID}); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		user     *model.User
+		listing  bool
+		expected []string
+	}{
+		{user: nil, listing: true, expected: []string{"hidden", "private", "ro"}},
+		{user: carol, listing: true, expected: []string{"ro"}},
+		{user: carol, listing: false, expected: []string{"hidden", "ro"}},
+		{user: bob, listing: true, expected: []string{"private", "ro"}},
+		{user: &model.User{Username: "alice"}, listing: true, expected: []string{"private", "ro"}},
+		{user: &model.User{Username: "guest", Role: model.GUEST}, listing: true, expected: []string{"private", "ro"}},
+		{user: &model.User{Username: "admin", Role: model.ADMIN, Permission: 1}, listing: true, expected: []string{"hidden", "private", "ro"}},
+	}
+	for _, c := range cases {
+		var names []string
+		for _, f := range op.GetVisibleStorageVirtualFilesByPath("/m", c.user, c.listing) {
+			names = append(names, f.GetName())
+		}
+		sort.Strings(names)
+		if !utils.SliceEqual(names, c.expected) {
+			t.Errorf("user %+v, listing %v: expected %v, got %v", c.user, c.listing, c.expected, names)
+		}
+	}
+}
@@ -82,6 +82,18 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err := db.DeleteAPITokensByUserId(id); err != nil {
+		return err
+	}
+	if err := DeleteSessionsByUserId(id); err != nil {
+		return err
+	}
+	if err := db.DeleteScimGroupMembersByUserId(id); err != nil {
+		return err
+	}
+	if err := db.DeleteRecoveryCodesByUserId(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -90,7 +102,7 @@ func UpdateUser(u *model.User) error {
 	if err != nil {
 		return err
 	}
-	if u.IsAdmin() {
+	if u.IsAdmin() || old.IsAdmin() {
 		adminUser = nil
 	}
 	if u.IsGuest() {
@@ -103,6 +115,9 @@ func UpdateUser(u *model.User) error {
 
 func Cancel2FAByUser(u *model.User) error {
 	u.OtpSecret = ""
+	if err := db.DeleteRecoveryCodesByUserId(u.ID); err != nil {
+		return err
+	}
 	return UpdateUser(u)
 }
 
@@ -128,3 +143,23 @@ func DelUserCache(username string) error {
 	userCache.Del(username)
 	return nil
 }
+
+// SetMfaEnrollBy sets the 2FA enrolment deadline of the user if it is not set yet
+func SetMfaEnrollBy(u *model.User, deadline int64) error {
+	if err := db.SetMfaEnrollBy(u.ID, deadline); err != nil {
+		return err
+	}
+	if u.IsAdmin() {
+		adminUser = nil
+	}
+	userCache.Del(u.Username)
+	return nil
+}
+
+// ResetMfaEnrollBy clears the 2FA enrolment deadlines which are not passed yet,
+// the users whose grace period is already over stay blocked until they enroll
+func ResetMfaEnrollBy() error {
+	userCache.Clear()
+	adminUser, guestUser = nil, nil
+	return db.ResetMfaEnrollBy(time.Now().Unix())
+}
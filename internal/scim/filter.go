@@ -0,0 +1,381 @@
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// Filter is a parsed scim filter, see https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2
+type Filter interface {
+	// Match reports whether the resource, in its json form, matches the filter
+	Match(r map[string]any) bool
+}
+
+type logicFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f *logicFilter) Match(r map[string]any) bool {
+	if f.and {
+		return f.left.Match(r) && f.right.Match(r)
+	}
+	return f.left.Match(r) || f.right.Match(r)
+}
+
+type notFilter struct {
+	f Filter
+}
+
+func (f *notFilter) Match(r map[string]any) bool {
+	return !f.f.Match(r)
+}
+
+// valuePathFilter matches multi-valued attributes such as members[value eq "1"]
+type valuePathFilter struct {
+	attr string
+	f    Filter
+}
+
+func (f *valuePathFilter) Match(r map[string]any) bool {
+	for _, v := range Values(r, f.attr) {
+		if m, ok := v.(map[string]any); ok && f.f.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	attr  string
+	op    string
+	value any
+}
+
+func (f *compareFilter) Match(r map[string]any) bool {
+	values := Values(r, f.attr)
+	if f.op == "pr" {
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range values {
+		// a multi-valued attribute is compared by its value sub-attribute
+		if m, ok := v.(map[string]any); ok {
+			v = Get(m, "value")
+		}
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return f.op == "ne" && len(values) == 0
+}
+
+func compare(v any, op string, want any) bool {
+	if n, ok := want.(float64); ok {
+		got, ok := toFloat(v)
+		if !ok {
+			return op == "ne"
+		}
+		switch op {
+		case "eq":
+			return got == n
+		case "ne":
+			return got != n
+		case "gt":
+			return got > n
+		case "ge":
+			return got >= n
+		case "lt":
+			return got < n
+		case "le":
+			return got <= n
+		}
+		return false
+	}
+	// strings are compared case-insensitively, as all the attributes alist serves are caseExact false
+	got, w := strings.ToLower(toString(v)), strings.ToLower(toString(want))
+	switch op {
+	case "eq":
+		return got == w
+	case "ne":
+		return got != w
+	case "co":
+		return strings.Contains(got, w)
+	case "sw":
+		return strings.HasPrefix(got, w)
+	case "ew":
+		return strings.HasSuffix(got, w)
+	case "gt":
+		return got > w
+	case "ge":
+		return got >= w
+	case "lt":
+		return got < w
+	case "le":
+		return got <= w
+	}
+	return false
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// attrName strips the schema urn of an attribute path,
+// e.g. urn:ietf:params:scim:schemas:core:2.0:User:userName is userName
+func attrName(path string) string {
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		if i := strings.LastIndex(path, ":"); i >= 0 {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+// Get returns the value of the attribute of the resource, names are case-insensitive
+func Get(r map[string]any, name string) any {
+	if v, ok := r[name]; ok {
+		return v
+	}
+	for k, v := range r {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Values returns the values of an attribute path such as name.familyName,
+// multi-valued attributes are flattened
+func Values(r map[string]any, path string) []any {
+	values := []any{r}
+	for _, name := range strings.Split(attrName(path), ".") {
+		var next []any
+		for _, v := range values {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch sub := Get(m, name).(type) {
+			case nil:
+			case []any:
+				next = append(next, sub...)
+			default:
+				next = append(next, sub)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+var compareOps = []string{"eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le"}
+
+// ParseFilter parses a filter such as userName eq "bob" and not (emails pr)
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in filter", p.tokens[p.pos])
+	}
+	return f, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Filter, error) {
+	if !strings.EqualFold(p.peek(), "not") {
+		return p.parseAtom()
+	}
+	p.pos++
+	if p.peek() != "(" {
+		return nil, fmt.Errorf("expect ( after not")
+	}
+	f, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	return &notFilter{f: f}, nil
+}
+
+func (p *parser) parseAtom() (Filter, error) {
+	t := p.next()
+	switch t {
+	case "":
+		return nil, fmt.Errorf("unexpected end of filter")
+	case "(":
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ) in filter")
+		}
+		return f, nil
+	case ")", "[", "]":
+		return nil, fmt.Errorf("unexpected %q in filter", t)
+	}
+	attr := t
+	if p.peek() == "[" {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != "]" {
+			return nil, fmt.Errorf("missing ] in filter")
+		}
+		return &valuePathFilter{attr: attr, f: f}, nil
+	}
+	op := strings.ToLower(p.next())
+	if op == "pr" {
+		return &compareFilter{attr: attr, op: op}, nil
+	}
+	if !utils.SliceContains(compareOps, op) {
+		return nil, fmt.Errorf("invalid operator %q in filter", op)
+	}
+	value, err := parseValue(p.next())
+	if err != nil {
+		return nil, err
+	}
+	return &compareFilter{attr: attr, op: op, value: value}, nil
+}
+
+func parseValue(t string) (any, error) {
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("missing value in filter")
+	case strings.HasPrefix(t, `"`):
+		return strconv.Unquote(t)
+	case t == "true" || t == "false":
+		return t, nil
+	case t == "null":
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q in filter", t)
+	}
+	return f, nil
+}
+
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in filter")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := strings.IndexFunc(s[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune("()[]\"", r)
+			})
+			if j < 0 {
+				j = len(s) - i
+			}
+			tokens = append(tokens, s[i:i+j])
+			i += j
+		}
+	}
+	return tokens, nil
+}
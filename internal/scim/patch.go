@@ -0,0 +1,207 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PatchOperation is an operation of a PatchOp request, see https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// patchPath is a parsed path such as members[value eq "1"].display
+type patchPath struct {
+	attr   string
+	filter Filter
+	sub    string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	p := &patchPath{}
+	if i := strings.Index(path, "["); i >= 0 {
+		j := strings.LastIndex(path, "]")
+		if j < i {
+			return nil, fmt.Errorf("missing ] in path %q", path)
+		}
+		f, err := ParseFilter(path[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+		p.attr, p.filter = attrName(path[:i]), f
+		p.sub = strings.TrimPrefix(path[j+1:], ".")
+		return p, nil
+	}
+	p.attr = attrName(path)
+	if i := strings.Index(p.attr, "."); i >= 0 {
+		p.attr, p.sub = p.attr[:i], p.attr[i+1:]
+	}
+	return p, nil
+}
+
+// key returns the key of the attribute in the resource, names are case-insensitive
+func key(r map[string]any, name string) string {
+	for k := range r {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// ApplyPatch applies the operations to the resource in its json form
+func ApplyPatch(r map[string]any, ops []PatchOperation) error {
+	for _, op := range ops {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			err = apply(r, op.Path, op.Value, strings.EqualFold(op.Op, "add"))
+		case "remove":
+			err = remove(r, op.Path, op.Value)
+		default:
+			err = fmt.Errorf("invalid patch op %q", op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func apply(r map[string]any, path string, value any, add bool) error {
+	if path == "" {
+		values, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("value must be an object if path is omitted")
+		}
+		for k, v := range values {
+			if err := apply(r, k, v, add); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	k := key(r, p.attr)
+	if p.filter != nil {
+		elems, _ := r[k].([]any)
+		matched := false
+		for i, e := range elems {
+			m, ok := e.(map[string]any)
+			if !ok || !p.filter.Match(m) {
+				continue
+			}
+			matched = true
+			if p.sub == "" {
+				elems[i] = value
+			} else {
+				m[key(m, p.sub)] = value
+			}
+		}
+		if !matched {
+			return fmt.Errorf("no target matches path %q", path)
+		}
+		return nil
+	}
+	if p.sub != "" {
+		m, ok := r[k].(map[string]any)
+		if !ok {
+			m = map[string]any{}
+			r[k] = m
+		}
+		m[key(m, p.sub)] = value
+		return nil
+	}
+	if old, ok := r[k].([]any); ok && add {
+		// add to a multi-valued attribute appends the values which are not present
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+		for _, v := range values {
+			if !containsValue(old, v) {
+				old = append(old, v)
+			}
+		}
+		r[k] = old
+		return nil
+	}
+	r[k] = value
+	return nil
+}
+
+func remove(r map[string]any, path string, value any) error {
+	if path == "" {
+		return fmt.Errorf("path is required to remove")
+	}
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	k := key(r, p.attr)
+	if p.filter == nil && p.sub != "" {
+		if m, ok := r[k].(map[string]any); ok {
+			delete(m, key(m, p.sub))
+		}
+		return nil
+	}
+	elems, isSlice := r[k].([]any)
+	if p.filter == nil {
+		// some identity providers remove members by the values instead of a filter
+		values, ok := value.([]any)
+		if !isSlice || !ok {
+			delete(r, k)
+			return nil
+		}
+		var rest []any
+		for _, e := range elems {
+			if !containsValue(values, e) {
+				rest = append(rest, e)
+			}
+		}
+		r[k] = rest
+		return nil
+	}
+	var rest []any
+	for _, e := range elems {
+		m, ok := e.(map[string]any)
+		if !ok || !p.filter.Match(m) {
+			rest = append(rest, e)
+			continue
+		}
+		if p.sub != "" {
+			delete(m, key(m, p.sub))
+			rest = append(rest, m)
+		}
+	}
+	r[k] = rest
+	return nil
+}
+
+// containsValue reports whether the values contain v, complex values are compared by their value sub-attribute
+func containsValue(values []any, v any) bool {
+	want := v
+	if m, ok := v.(map[string]any); ok {
+		want = Get(m, "value")
+	}
+	for _, e := range values {
+		got := e
+		if m, ok := e.(map[string]any); ok {
+			got = Get(m, "value")
+		}
+		if toString(got) == toString(want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// MaxResults is the max number of resources returned by a list request
+const MaxResults = 200
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type User struct {
+	Schemas  []string `json:"schemas"`
+	ID       string   `json:"id"`
+	UserName string   `json:"userName"`
+	Active   bool     `json:"active"`
+	Groups   []Member `json:"groups,omitempty"`
+	Meta     Meta     `json:"meta"`
+}
+
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members"`
+	Meta        Meta     `json:"meta"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// Error is the error response of scim, see https://datatracker.ietf.org/doc/html/rfc7644#section-3.12
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+	Status   string   `json:"status"`
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// Code returns the http status code of the error
+func (e *Error) Code() int {
+	code, err := strconv.Atoi(e.Status)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	return code
+}
+
+func NewError(code int, scimType string, format string, args ...any) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+		Status:   strconv.Itoa(code),
+	}
+}
+
+// ServiceProviderConfig describes the features supported by the server
+func ServiceProviderConfig() map[string]any {
+	supported := func(b bool) map[string]any {
+		return map[string]any{"supported": b}
+	}
+	return map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": MaxResults},
+		"changePassword": supported(true),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the scim token in the settings",
+		}},
+		"meta": Meta{ResourceType: "ServiceProviderConfig"},
+	}
+}
+
+// toMap converts a resource to its json form for filtering and patching
+func toMap(v any) (map[string]any, error) {
+	data, err := utils.Json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = utils.Json.Unmarshal(data, &m)
+	return m, err
+}
+
+// List filters and paginates the resources, startIndex is 1-based
+func List[T any](resources []T, filter string, startIndex, count int) (*ListResponse, error) {
+	var f Filter
+	if filter != "" {
+		var err error
+		f, err = ParseFilter(filter)
+		if err != nil {
+			return nil, NewError(http.StatusBadRequest, "invalidFilter", "%s", err.Error())
+		}
+	}
+	matched := make([]any, 0, len(resources))
+	for _, r := range resources {
+		if f != nil {
+			m, err := toMap(r)
+			if err != nil {
+				return nil, err
+			}
+			if !f.Match(m) {
+				continue
+			}
+		}
+		matched = append(matched, r)
+	}
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 || count > MaxResults {
+		count = MaxResults
+	}
+	start := min(startIndex-1, len(matched))
+	end := min(start+count, len(matched))
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: end - start,
+		Resources:    matched[start:end],
+	}, nil
+}
@@ -0,0 +1,413 @@
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func userResource(u *model.User) (*User, error) {
+	groups, err := db.GetUserScimGroups(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &User{
+		Schemas:  []string{SchemaUser},
+		ID:       strconv.FormatUint(uint64(u.ID), 10),
+		UserName: u.Username,
+		Active:   !u.Disabled,
+		Groups: utils.MustSliceConvert(groups, func(g model.ScimGroup) Member {
+			return Member{Value: strconv.FormatUint(uint64(g.ID), 10), Display: g.DisplayName}
+		}),
+		Meta: Meta{ResourceType: "User"},
+	}, nil
+}
+
+func parseID(id string) (uint, bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	return uint(n), err == nil
+}
+
+// getUser returns the user of the id, the admin and guest are not exposed through scim,
+// so the identity provider can't take over the admin
+func getUser(id string) (*model.User, error) {
+	n, ok := parseID(id)
+	if !ok {
+		return nil, NewError(http.StatusNotFound, "", "user %s not found", id)
+	}
+	u, err := db.GetUserById(n)
+	if err != nil || u.IsAdmin() || u.IsGuest() {
+		return nil, NewError(http.StatusNotFound, "", "user %s not found", id)
+	}
+	return u, nil
+}
+
+// setUser sets the attributes of the user from a scim user in its json form
+func setUser(u *model.User, r map[string]any) error {
+	if v := Get(r, "userName"); v != nil {
+		username := toString(v)
+		if username == "" {
+			return NewError(http.StatusBadRequest, "invalidValue", "userName is required")
+		}
+		if username != u.Username {
+			if _, err := db.GetUserByName(username); err == nil {
+				return NewError(http.StatusConflict, "uniqueness", "user %s already exists", username)
+			}
+		}
+		u.Username = username
+	}
+	if v := Get(r, "active"); v != nil {
+		// some identity providers send booleans as strings in patch requests
+		active, err := strconv.ParseBool(strings.ToLower(toString(v)))
+		if err != nil {
+			return NewError(http.StatusBadRequest, "invalidValue", "invalid active %v", v)
+		}
+		u.Disabled = !active
+	}
+	if password := toString(Get(r, "password")); password != "" {
+		u.SetPassword(password)
+	}
+	return nil
+}
+
+func Users() ([]User, error) {
+	users, err := db.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]User, 0, len(users))
+	for i := range users {
+		if users[i].IsAdmin() || users[i].IsGuest() {
+			continue
+		}
+		u, err := userResource(&users[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *u)
+	}
+	return res, nil
+}
+
+func GetUser(id string) (*User, error) {
+	u, err := getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return userResource(u)
+}
+
+func CreateUser(r map[string]any) (*User, error) {
+	if toString(Get(r, "userName")) == "" {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	u := &model.User{
+		Role:       model.GENERAL,
+		Permission: int32(setting.GetInt(conf.ScimDefaultPermission, 0)),
+		BasePath:   setting.GetStr(conf.ScimDefaultDir),
+		Authn:      "[]",
+	}
+	u.SetPassword(random.String(16))
+	if err := setUser(u, r); err != nil {
+		return nil, err
+	}
+	if err := op.CreateUser(u); err != nil {
+		return nil, err
+	}
+	return userResource(u)
+}
+
+func ReplaceUser(id string, r map[string]any) (*User, error) {
+	u, err := getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if toString(Get(r, "userName")) == "" {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	if err := setUser(u, r); err != nil {
+		return nil, err
+	}
+	if err := op.UpdateUser(u); err != nil {
+		return nil, err
+	}
+	return userResource(u)
+}
+
+func PatchUser(id string, ops []PatchOperation) (*User, error) {
+	u, err := getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := userResource(u)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toMap(res)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyPatch(r, ops); err != nil {
+		return nil, NewError(http.StatusBadRequest, "invalidPath", "%s", err.Error())
+	}
+	if err := setUser(u, r); err != nil {
+		return nil, err
+	}
+	if err := op.UpdateUser(u); err != nil {
+		return nil, err
+	}
+	return userResource(u)
+}
+
+func DeleteUser(id string) error {
+	u, err := getUser(id)
+	if err != nil {
+		return err
+	}
+	err = op.DeleteUserById(u.ID)
+	if errors.Is(err, errs.DeleteAdminOrGuest) {
+		return NewError(http.StatusBadRequest, "mutability", "%s", err.Error())
+	}
+	return err
+}
+
+func groupResource(g *model.ScimGroup) (*Group, error) {
+	ids, err := db.GetScimGroupMembers(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]Member, 0, len(ids))
+	for _, id := range ids {
+		m := Member{Value: strconv.FormatUint(uint64(id), 10)}
+		if u, err := db.GetUserById(id); err == nil {
+			m.Display = u.Username
+		}
+		members = append(members, m)
+	}
+	return &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          strconv.FormatUint(uint64(g.ID), 10),
+		ExternalID:  g.ExternalID,
+		DisplayName: g.DisplayName,
+		Members:     members,
+		Meta:        Meta{ResourceType: "Group"},
+	}, nil
+}
+
+func getGroup(id string) (*model.ScimGroup, error) {
+	n, ok := parseID(id)
+	if !ok {
+		return nil, NewError(http.StatusNotFound, "", "group %s not found", id)
+	}
+	g, err := db.GetScimGroupById(n)
+	if err != nil {
+		return nil, NewError(http.StatusNotFound, "", "group %s not found", id)
+	}
+	return g, nil
+}
+
+// setGroup sets the attributes of the group from a scim group in its json form,
+// it returns the ids of the members
+func setGroup(g *model.ScimGroup, r map[string]any) ([]uint, error) {
+	name := toString(Get(r, "displayName"))
+	if name == "" {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	if name != g.DisplayName {
+		groups, err := db.GetScimGroups()
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range groups {
+			if other.ID != g.ID && strings.EqualFold(other.DisplayName, name) {
+				return nil, NewError(http.StatusConflict, "uniqueness", "group %s already exists", name)
+			}
+		}
+	}
+	g.DisplayName = name
+	g.ExternalID = toString(Get(r, "externalId"))
+	var ids []uint
+	members, _ := Get(r, "members").([]any)
+	for _, m := range members {
+		value := m
+		if mm, ok := m.(map[string]any); ok {
+			value = Get(mm, "value")
+		}
+		id, ok := parseID(toString(value))
+		if !ok {
+			return nil, NewError(http.StatusBadRequest, "invalidValue", "invalid member %v", value)
+		}
+		if _, err := getUser(toString(value)); err != nil {
+			return nil, NewError(http.StatusBadRequest, "invalidValue", "member %v not found", value)
+		}
+		if !utils.SliceContains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// saveGroup saves the group and its members, then applies the group mapping to the affected users
+func saveGroup(g *model.ScimGroup, members []uint) error {
+	var old []uint
+	if g.ID == 0 {
+		if err := db.CreateScimGroup(g); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		old, err = db.GetScimGroupMembers(g.ID)
+		if err != nil {
+			return err
+		}
+		if err := db.UpdateScimGroup(g); err != nil {
+			return err
+		}
+	}
+	if err := db.SetScimGroupMembers(g.ID, members); err != nil {
+		return err
+	}
+	for _, id := range members {
+		if !utils.SliceContains(old, id) {
+			old = append(old, id)
+		}
+	}
+	return applyGroupMapping(old)
+}
+
+func Groups() ([]Group, error) {
+	groups, err := db.GetScimGroups()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Group, 0, len(groups))
+	for i := range groups {
+		g, err := groupResource(&groups[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *g)
+	}
+	return res, nil
+}
+
+func GetGroup(id string) (*Group, error) {
+	g, err := getGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	return groupResource(g)
+}
+
+func CreateGroup(r map[string]any) (*Group, error) {
+	g := &model.ScimGroup{}
+	members, err := setGroup(g, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveGroup(g, members); err != nil {
+		return nil, err
+	}
+	return groupResource(g)
+}
+
+func ReplaceGroup(id string, r map[string]any) (*Group, error) {
+	g, err := getGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := setGroup(g, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveGroup(g, members); err != nil {
+		return nil, err
+	}
+	return groupResource(g)
+}
+
+func PatchGroup(id string, ops []PatchOperation) (*Group, error) {
+	g, err := getGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := groupResource(g)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toMap(res)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyPatch(r, ops); err != nil {
+		return nil, NewError(http.StatusBadRequest, "invalidPath", "%s", err.Error())
+	}
+	members, err := setGroup(g, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveGroup(g, members); err != nil {
+		return nil, err
+	}
+	return groupResource(g)
+}
+
+func DeleteGroup(id string) error {
+	g, err := getGroup(id)
+	if err != nil {
+		return err
+	}
+	members, err := db.GetScimGroupMembers(g.ID)
+	if err != nil {
+		return err
+	}
+	if err := db.DeleteScimGroupById(g.ID); err != nil {
+		return err
+	}
+	return applyGroupMapping(members)
+}
+
+// applyGroupMapping sets the permissions of the users by the groups they are members of,
+// it does nothing if no group mapping is configured
+func applyGroupMapping(userIds []uint) error {
+	var rules []model.GroupRule
+	if err := utils.Json.UnmarshalFromString(setting.GetStr(conf.ScimGroupMapping, "[]"), &rules); err != nil {
+		log.Warnf("invalid scim group mapping: %+v", err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	defPermission := int32(setting.GetInt(conf.ScimDefaultPermission, 0))
+	defDir := setting.GetStr(conf.ScimDefaultDir)
+	for _, id := range userIds {
+		u, err := db.GetUserById(id)
+		if err != nil || u.IsAdmin() || u.IsGuest() {
+			continue
+		}
+		groups, err := db.GetUserScimGroups(id)
+		if err != nil {
+			return err
+		}
+		names := utils.MustSliceConvert(groups, func(g model.ScimGroup) string {
+			return g.DisplayName
+		})
+		if model.ApplyGroupRules(rules, names, defPermission, defDir, u) {
+			if err := op.UpdateUser(u); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
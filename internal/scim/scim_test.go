@@ -0,0 +1,108 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func resource(t *testing.T, s string) map[string]any {
+	var r map[string]any
+	if err := json.Unmarshal([]byte(s), &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFilter(t *testing.T) {
+	r := resource(t, `{
+		"userName": "Alice",
+		"active": true,
+		"name": {"familyName": "Smith"},
+		"emails": [{"value": "alice@example.com", "type": "work"}],
+		"meta": {"resourceType": "User"}
+	}`)
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{`userName eq "alice"`, true},
+		{`UserName Eq "bob"`, false},
+		{`userName ne "bob"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "al"`, true},
+		{`name.familyName co "mit"`, true},
+		{`emails co "example.com"`, true},
+		{`emails[type eq "work" and value ew ".com"]`, true},
+		{`emails[type eq "home"]`, false},
+		{`active eq true`, true},
+		{`title pr`, false},
+		{`userName eq "bob" or (active eq true and not (title pr))`, true},
+		{`meta.resourceType eq "Group"`, false},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("parse %s: %v", tt.filter, err)
+			continue
+		}
+		if got := f.Match(r); got != tt.match {
+			t.Errorf("%s: got %v, want %v", tt.filter, got, tt.match)
+		}
+	}
+	for _, s := range []string{`userName`, `userName xx "a"`, `(userName eq "a"`, `userName eq "a`, `not userName pr`} {
+		if _, err := ParseFilter(s); err == nil {
+			t.Errorf("expect error for %s", s)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	r := resource(t, `{
+		"displayName": "writers",
+		"members": [{"value": "1"}, {"value": "2"}]
+	}`)
+	err := ApplyPatch(r, []PatchOperation{
+		{Op: "Add", Path: "members", Value: []any{map[string]any{"value": "3"}, map[string]any{"value": "1"}}},
+		{Op: "remove", Path: `members[value eq "2"]`},
+		{Op: "Replace", Value: map[string]any{"displayName": "editors"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r["displayName"] != "editors" {
+		t.Errorf("got displayName %v", r["displayName"])
+	}
+	var ids []string
+	for _, m := range Values(r, "members.value") {
+		ids = append(ids, m.(string))
+	}
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "3" {
+		t.Errorf("got members %v", ids)
+	}
+	err = ApplyPatch(r, []PatchOperation{
+		{Op: "remove", Path: "members", Value: []any{map[string]any{"value": "1"}}},
+		{Op: "replace", Path: "active", Value: "False"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r["members"].([]any)) != 1 || r["active"] != "False" {
+		t.Errorf("got %v", r)
+	}
+	if err := ApplyPatch(r, []PatchOperation{{Op: "move", Path: "a"}}); err == nil {
+		t.Error("expect error for invalid op")
+	}
+}
+
+func TestList(t *testing.T) {
+	users := []User{{UserName: "a"}, {UserName: "b"}, {UserName: "c"}}
+	res, err := List(users, `userName ne "b"`, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalResults != 2 || res.ItemsPerPage != 1 || res.Resources[0].(User).UserName != "c" {
+		t.Errorf("got %+v", res)
+	}
+	if _, err := List(users, `userName eq`, 1, 10); err == nil {
+		t.Error("expect error for invalid filter")
+	}
+}
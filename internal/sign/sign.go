@@ -36,6 +36,17 @@ func Verify(data string, sign string) error {
 	return instance.Verify(data, sign)
 }
 
+// Scoped signs data for the signed link identified by key, so the sign can't be used with another link
+func Scoped(data string, key string, expire int64) string {
+	once.Do(Instance)
+	return instance.Sign(data+"#"+key, expire)
+}
+
+func VerifyScoped(data string, key string, sign string) error {
+	once.Do(Instance)
+	return instance.Verify(data+"#"+key, sign)
+}
+
 func Instance() {
 	instance = sign.NewHMACSign([]byte(setting.GetStr(conf.Token)))
 }
@@ -0,0 +1,114 @@
+package sso
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Provider is an oidc provider configured in sso_oidc_providers, several providers can be used at the same time
+type Provider struct {
+	Name         string   `json:"name"`
+	Endpoint     string   `json:"endpoint"` // the issuer url
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"` // besides openid and profile, e.g. groups
+	PKCE         bool     `json:"pkce"`
+	// UsernameKey and GroupsKey are the claims of the id token, nested claims are separated by dots, e.g. realm_access.roles
+	UsernameKey string `json:"username_key"`
+	GroupsKey   string `json:"groups_key"`
+	// users are registered on their first login if AutoRegister is true
+	AutoRegister      bool              `json:"auto_register"`
+	DefaultDir        string            `json:"default_dir"`
+	DefaultPermission int32             `json:"default_permission"`
+	GroupMapping      []model.GroupRule `json:"group_mapping"`
+}
+
+func GetProviders() ([]Provider, error) {
+	var providers []Provider
+	value := setting.GetStr(conf.SSOOIDCProviders)
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	if err := utils.Json.UnmarshalFromString(value, &providers); err != nil {
+		return nil, errors.Wrap(err, "invalid oidc providers")
+	}
+	return providers, nil
+}
+
+func GetProvider(name string) (*Provider, error) {
+	providers, err := GetProviders()
+	if err != nil {
+		return nil, err
+	}
+	for i := range providers {
+		if providers[i].Name == name {
+			return &providers[i], nil
+		}
+	}
+	return nil, errors.Errorf("oidc provider not found: %s", name)
+}
+
+// SsoID is the sso id of the user of the provider, prefixed by the name of the provider
+// so that users of different providers don't collide
+func (p *Provider) SsoID(username string) string {
+	return p.Name + ":" + username
+}
+
+// Apply maps the groups in the claims to the user, it does nothing if there is no group mapping
+func (p *Provider) Apply(claims map[string]any, user *model.User) bool {
+	if len(p.GroupMapping) == 0 {
+		return false
+	}
+	return model.ApplyGroupRules(p.GroupMapping, ClaimStrings(claims, p.GroupsKey), p.DefaultPermission, p.DefaultDir, user)
+}
+
+func claim(claims map[string]any, key string) any {
+	var v any = claims
+	for _, k := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+// ClaimString returns the string claim, numbers are formatted
+func ClaimString(claims map[string]any, key string) string {
+	switch v := claim(claims, key).(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// ClaimStrings returns the claim as a list, a single string is split by commas
+func ClaimStrings(claims map[string]any, key string) []string {
+	switch v := claim(claims, key).(type) {
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package sso
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestClaims(t *testing.T) {
+	var claims map[string]any
+	err := json.Unmarshal([]byte(`{
+		"sub": "f1b2",
+		"uid": 1001,
+		"preferred_username": "alice",
+		"groups": ["/writers", "readers"],
+		"realm_access": {"roles": ["admin", "user"]},
+		"scope": "a,b"
+	}`), &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ClaimString(claims, "preferred_username"); s != "alice" {
+		t.Errorf("got username %q", s)
+	}
+	if s := ClaimString(claims, "uid"); s != "1001" {
+		t.Errorf("got uid %q", s)
+	}
+	if s := ClaimString(claims, "missing.key"); s != "" {
+		t.Errorf("got missing %q", s)
+	}
+	if got := ClaimStrings(claims, "realm_access.roles"); !reflect.DeepEqual(got, []string{"admin", "user"}) {
+		t.Errorf("got roles %v", got)
+	}
+	if got := ClaimStrings(claims, "scope"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got scope %v", got)
+	}
+
+	p := Provider{
+		GroupsKey:  "groups",
+		DefaultDir: "/",
+		GroupMapping: []model.GroupRule{
+			{Group: "/writers", Permission: 8, BasePath: "/team"},
+		},
+	}
+	user := &model.User{}
+	if !p.Apply(claims, user) || user.Permission != 8 || user.BasePath != "/team" {
+		t.Errorf("group mapping not applied: %+v", user)
+	}
+}
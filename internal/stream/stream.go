@@ -22,6 +22,8 @@ type FileStream struct {
 	WebPutAsTask      bool
 	ForceStreamUpload bool
 	Exist             model.Obj //the file existed in the destination, we can reuse some info since we wil overwrite it
+	// the hashes of the content, e.g. the ones of the source when copied, the ones of Obj are used if empty
+	HashInfo utils.HashInfo
 	utils.Closers
 	tmpFile  *os.File //if present, tmpFile has full content, it will be deleted at last
 	peekBuff *bytes.Reader
@@ -66,6 +68,17 @@ func (f *FileStream) Close() error {
 	return errors.Join(err1, err2)
 }
 
+func (f *FileStream) GetHash() utils.HashInfo {
+	if len(f.HashInfo.Export()) > 0 {
+		return f.HashInfo
+	}
+	return f.Obj.GetHash()
+}
+
+func (f *FileStream) SetHash(hi utils.HashInfo) {
+	f.HashInfo = hi
+}
+
 func (f *FileStream) GetExist() model.Obj {
 	return f.Exist
 }
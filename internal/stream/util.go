@@ -2,14 +2,18 @@ package stream
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -86,3 +90,60 @@ func checkContentRange(header *http.Header, offset int64) bool {
 	}
 	return false
 }
+
+// RapidUploadMinSize is the min size of the files hashed before uploaded for the rapid upload,
+// the smaller ones are uploaded faster than read twice
+const RapidUploadMinSize = utils.MB
+
+// CalcHash computes the hashes of the types which the stream lacks by reading the whole content,
+// the content is cached in a temp file if not seekable, which is then reused by the upload
+func CalcHash(s model.FileStreamer, types ...*utils.HashType) error {
+	setter, ok := s.(interface{ SetHash(utils.HashInfo) })
+	if !ok {
+		return nil
+	}
+	hashes := make(map[*utils.HashType]string)
+	for t, v := range s.GetHash().Export() {
+		hashes[t] = v
+	}
+	hashers := make(map[*utils.HashType]hash.Hash)
+	writers := make([]io.Writer, 0, len(types))
+	for _, t := range types {
+		if len(hashes[t]) == t.Width {
+			continue
+		}
+		h := t.NewFunc(s.GetSize())
+		hashers[t] = h
+		writers = append(writers, h)
+	}
+	if len(hashers) == 0 {
+		return nil
+	}
+	file, err := s.CacheFullInTempFile()
+	if err != nil {
+		return err
+	}
+	if _, err = utils.CopyWithBuffer(io.MultiWriter(writers...), io.NewSectionReader(file, 0, s.GetSize())); err != nil {
+		return err
+	}
+	for t, h := range hashers {
+		hashes[t] = hex.EncodeToString(h.Sum(nil))
+	}
+	setter.SetHash(utils.NewHashInfoByMap(hashes))
+	return nil
+}
+
+// PrepareRapidUpload computes the hashes needed by the rapid upload of the dst storage if it pays off,
+// i.e. the file is not small and the source doesn't provide the hashes
+func PrepareRapidUpload(dst driver.Driver, s model.FileStreamer) error {
+	r, ok := dst.(driver.RapidUpload)
+	if !ok || s.GetSize() < RapidUploadMinSize {
+		return nil
+	}
+	types := r.RapidUploadHash()
+	if len(types) == 0 {
+		return nil
+	}
+	log.Debugf("compute hashes of [%s] for the rapid upload", s.GetName())
+	return CalcHash(s, types...)
+}
@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type rapidDriver struct {
+	driver.Driver
+	types []*utils.HashType
+}
+
+func (d rapidDriver) RapidUploadHash() []*utils.HashType {
+	return d.types
+}
+
+func newStream(data []byte, hi utils.HashInfo) *SeekableStream {
+	ss, _ := NewSeekableStream(FileStream{
+		Ctx:      context.Background(),
+		Obj:      &model.Object{Name: "a.bin", Size: int64(len(data))},
+		Reader:   model.NewNopMFile(bytes.NewReader(data)),
+		HashInfo: hi,
+	}, nil)
+	return ss
+}
+
+func TestCalcHash(t *testing.T) {
+	data := bytes.Repeat([]byte("alist"), 1000)
+	// the known hash is kept, even if it's not the real one
+	ss := newStream(data, utils.NewHashInfo(utils.SHA1, "FAKE"+utils.HashData(utils.SHA1, data)[4:]))
+	if err := CalcHash(ss, utils.MD5, utils.SHA1); err != nil {
+		t.Fatal(err)
+	}
+	hi := ss.GetHash()
+	if hi.GetHash(utils.MD5) != utils.HashData(utils.MD5, data) {
+		t.Errorf("md5 = %s", hi.GetHash(utils.MD5))
+	}
+	if hi.GetHash(utils.SHA1)[:4] != "FAKE" {
+		t.Errorf("known sha1 is recomputed: %s", hi.GetHash(utils.SHA1))
+	}
+	// the content is still read from the start
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(ss); err != nil || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("content changed by hashing: %v", err)
+	}
+}
+
+func TestPrepareRapidUpload(t *testing.T) {
+	small := newStream([]byte("alist"), utils.HashInfo{})
+	if err := PrepareRapidUpload(rapidDriver{types: []*utils.HashType{utils.MD5}}, small); err != nil {
+		t.Fatal(err)
+	}
+	if small.GetHash().GetHash(utils.MD5) != "" {
+		t.Error("small file is hashed")
+	}
+	data := bytes.Repeat([]byte{1}, RapidUploadMinSize)
+	big := newStream(data, utils.HashInfo{})
+	if err := PrepareRapidUpload(rapidDriver{}, big); err != nil {
+		t.Fatal(err)
+	}
+	if big.GetHash().GetHash(utils.MD5) != "" {
+		t.Error("hashed for the driver with the rapid upload disabled")
+	}
+	if err := PrepareRapidUpload(rapidDriver{types: []*utils.HashType{utils.MD5}}, big); err != nil {
+		t.Fatal(err)
+	}
+	if big.GetHash().GetHash(utils.MD5) != utils.HashData(utils.MD5, data) {
+		t.Error("big file is not hashed")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
@@ -17,6 +19,7 @@ type Client interface {
 	GetInfo(id string) (TorrentInfo, error)
 	GetFiles(id string) ([]FileInfo, error)
 	Delete(id string, deleteFiles bool) error
+	SetFilePriority(id string, indexes []int, priority int) error
 }
 
 type client struct {
@@ -364,3 +367,32 @@ func (c *client) Delete(id string, deleteFiles bool) error {
 	}
 	return nil
 }
+
+// SetFilePriority sets the priority of the files of a torrent, 0 means do not download
+func (c *client) SetFilePriority(id string, indexes []int, priority int) error {
+	err := c.checkAuthorization()
+	if err != nil {
+		return err
+	}
+
+	info, err := c.GetInfo(id)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(indexes))
+	for i, index := range indexes {
+		ids[i] = strconv.Itoa(index)
+	}
+	v := url.Values{}
+	v.Set("hash", info.Hash)
+	v.Set("id", strings.Join(ids, "|"))
+	v.Set("priority", strconv.Itoa(priority))
+	response, err := c.post("/api/v2/torrents/filePrio", v)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != 200 {
+		return errors.New("failed to set qbittorrent file priority")
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package random
 
 import (
+	crand "crypto/rand"
+	"math/big"
 	"math/rand"
 	"time"
 
@@ -19,6 +21,20 @@ func String(n int) string {
 	return string(b)
 }
 
+// SecureString is like String but reads crypto/rand, it is used for secrets which must not be guessed
+func SecureString(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(letterBytes)))
+	for i := range b {
+		j, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = letterBytes[j.Int64()]
+	}
+	return string(b), nil
+}
+
 func Token() string {
 	return "alist-" + uuid.NewString() + String(64)
 }
@@ -3,9 +3,13 @@ package common
 import (
 	"time"
 
-	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
+	"github.com/alist-org/alist/v3/internal/login"
+	"github.com/alist-org/alist/v3/internal/mfa"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 )
@@ -13,38 +17,96 @@ import (
 var SecretKey []byte
 
 type UserClaims struct {
-	Username string `json:"username"`
-	PwdTS    int64  `json:"pwd_ts"`
+	Username  string `json:"username"`
+	PwdTS     int64  `json:"pwd_ts"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-var validTokenCache = cache.NewMemCache[bool]()
+type TokenResp struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+func accessTokenLifetime() time.Duration {
+	if conf.Conf.AccessTokenExpiresIn > 0 {
+		return time.Duration(conf.Conf.AccessTokenExpiresIn) * time.Minute
+	}
+	return time.Duration(conf.Conf.TokenExpiresIn) * time.Hour
+}
+
+// Login creates a session for the user on the device of the request and issues its tokens,
+// method is how the user is authenticated, see model.LoginMethodPassword
+func Login(c *gin.Context, user *model.User, method string) (*TokenResp, error) {
+	return LoginWith(c, user, &model.Session{Method: method})
+}
+
+// LoginWith is Login with the session prefilled, e.g. with the sso fields
+func LoginWith(c *gin.Context, user *model.User, s *model.Session) (*TokenResp, error) {
+	if user.Disabled {
+		return nil, errors.New("current user is disabled")
+	}
+	s.Device, s.IP = c.Request.UserAgent(), ipfilter.ClientIP(c.Request)
+	mfa.StartGracePeriod(user)
+	refresh, err := op.CreateSession(user, s)
+	if err != nil {
+		return nil, err
+	}
+	login.Succeeded(&model.LoginAttempt{
+		Username:  user.Username,
+		IP:        s.IP,
+		Method:    s.Method,
+		UserAgent: s.Device,
+	})
+	token, err := GenerateToken(user, s.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResp{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(accessTokenLifetime().Seconds()),
+	}, nil
+}
 
-func GenerateToken(user *model.User) (tokenString string, err error) {
+// Refresh issues a new access token and rotates the refresh token
+func Refresh(refreshToken string) (*TokenResp, error) {
+	s, user, refresh, err := op.RefreshSession(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	mfa.StartGracePeriod(user)
+	token, err := GenerateToken(user, s.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResp{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(accessTokenLifetime().Seconds()),
+	}, nil
+}
+
+func GenerateToken(user *model.User, sessionID string) (tokenString string, err error) {
 	claim := UserClaims{
-		Username: user.Username,
-		PwdTS:    user.PwdTS,
+		Username:  user.Username,
+		PwdTS:     user.PwdTS,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(conf.Conf.TokenExpiresIn) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenLifetime())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		}}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	tokenString, err = token.SignedString(SecretKey)
-	if err != nil {
-		return "", err
-	}
-	validTokenCache.Set(tokenString, true)
-	return tokenString, err
+	return token.SignedString(SecretKey)
 }
 
 func ParseToken(tokenString string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return SecretKey, nil
 	})
-	if IsTokenInvalidated(tokenString) {
-		return nil, errors.New("token is invalidated")
-	}
 	if err != nil {
 		if ve, ok := err.(*jwt.ValidationError); ok {
 			if ve.Errors&jwt.ValidationErrorMalformed != 0 {
@@ -58,21 +120,28 @@ func ParseToken(tokenString string) (*UserClaims, error) {
 			}
 		}
 	}
-	if claims, ok := token.Claims.(*UserClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*UserClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("couldn't handle this token")
 	}
-	return nil, errors.New("couldn't handle this token")
+	// tokens are only valid while their session is active
+	if claims.SessionID == "" {
+		return nil, errors.New("token is invalidated")
+	}
+	if _, err := op.GetSession(claims.SessionID); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
+// InvalidateToken revokes the session of the token
 func InvalidateToken(tokenString string) error {
 	if tokenString == "" {
 		return nil // don't invalidate empty guest token
 	}
-	validTokenCache.Del(tokenString)
-	return nil
-}
-
-func IsTokenInvalidated(tokenString string) bool {
-	_, ok := validTokenCache.Get(tokenString)
-	return !ok
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil // already invalid
+	}
+	return op.DeleteSession(claims.SessionID)
 }
@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	stdpath "path"
 	"strings"
 
@@ -28,3 +29,13 @@ func GetApiUrl(r *http.Request) string {
 	api = strings.TrimSuffix(api, "/")
 	return api
 }
+
+// GetSiteOrigin returns the origin of the site, used as the target origin of the messages posted to the frontend
+func GetSiteOrigin(r *http.Request) string {
+	api := GetApiUrl(r)
+	u, err := url.Parse(api)
+	if err != nil || u.Host == "" {
+		return api
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,120 @@
+package handles
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAPITokenReq struct {
+	Name       string   `json:"name" binding:"required"`
+	Scopes     []string `json:"scopes" binding:"required"`
+	PathPrefix string   `json:"path_prefix"`
+	ExpireIn   int64    `json:"expire_in"` // in seconds, 0 means never
+}
+
+type CreateAPITokenResp struct {
+	model.APIToken
+	Token string `json:"token"`
+	// S3SecretKey pairs with the key as the s3 access key id
+	S3SecretKey string `json:"s3_secret_key"`
+}
+
+// CreateAPIToken creates a token for current user, the token is only shown once
+func CreateAPIToken(c *gin.Context) {
+	var req CreateAPITokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if req.ExpireIn < 0 {
+		common.ErrorStrResp(c, "expire_in can't be negative", 400)
+		return
+	}
+	for _, s := range req.Scopes {
+		if !user.CanGrant(strings.TrimSpace(s)) {
+			common.ErrorStrResp(c, "permission denied to create a token with the scope: "+s, 403)
+			return
+		}
+	}
+	t := &model.APIToken{
+		UserID:     user.ID,
+		Name:       req.Name,
+		Scopes:     strings.Join(req.Scopes, ","),
+		PathPrefix: req.PathPrefix,
+	}
+	if req.ExpireIn > 0 {
+		t.Expire = time.Now().Add(time.Duration(req.ExpireIn) * time.Second).Unix()
+	}
+	token, err := op.CreateAPIToken(t)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, CreateAPITokenResp{
+		APIToken:    *t,
+		Token:       token,
+		S3SecretKey: op.APITokenS3Secret(t),
+	})
+}
+
+func ListAPITokens(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	tokens, err := op.GetAPITokens(user.ID, model.KindAPIToken)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+// DeleteAPIToken revokes a token of current user
+func DeleteAPIToken(c *gin.Context) {
+	deleteAPIToken(c, false)
+}
+
+// ListAllAPITokens list the tokens of all users, or of the user given by user_id,
+// app passwords are listed if kind is app_password
+func ListAllAPITokens(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Query("user_id"))
+	tokens, err := op.GetAPITokens(uint(userId), c.Query("kind"))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+// AdminDeleteAPIToken revokes a token of any user
+func AdminDeleteAPIToken(c *gin.Context) {
+	deleteAPIToken(c, true)
+}
+
+func deleteAPIToken(c *gin.Context, any bool) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := op.GetAPITokenById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if !any && t.UserID != user.ID {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	if err := op.DeleteAPITokenById(t.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
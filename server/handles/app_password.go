@@ -0,0 +1,76 @@
+package handles
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAppPasswordReq struct {
+	Name       string `json:"name" binding:"required"`
+	ReadOnly   bool   `json:"read_only"`
+	PathPrefix string `json:"path_prefix"`
+	ExpireIn   int64  `json:"expire_in"` // in seconds, 0 means never
+}
+
+type CreateAppPasswordResp struct {
+	model.APIToken
+	Password string `json:"password"`
+	// S3SecretKey pairs with the key as the s3 access key id
+	S3SecretKey string `json:"s3_secret_key"`
+}
+
+// CreateAppPassword creates an app password for webdav and s3 clients of current user, the password is only shown once
+func CreateAppPassword(c *gin.Context) {
+	var req CreateAppPasswordReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.ExpireIn < 0 {
+		common.ErrorStrResp(c, "expire_in can't be negative", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	t := &model.APIToken{
+		UserID:     user.ID,
+		Name:       req.Name,
+		Scopes:     model.ScopeFsWrite,
+		PathPrefix: req.PathPrefix,
+	}
+	// a user without the write permissions gets a read only password
+	if req.ReadOnly || !user.CanGrant(model.ScopeFsWrite) {
+		t.Scopes = model.ScopeFsRead
+	}
+	if req.ExpireIn > 0 {
+		t.Expire = time.Now().Add(time.Duration(req.ExpireIn) * time.Second).Unix()
+	}
+	password, err := op.CreateAppPassword(t)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, CreateAppPasswordResp{
+		APIToken:    *t,
+		Password:    password,
+		S3SecretKey: op.APITokenS3Secret(t),
+	})
+}
+
+func ListAppPasswords(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	passwords, err := op.GetAPITokens(user.ID, model.KindAppPassword)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, passwords)
+}
+
+// DeleteAppPassword revokes an app password of current user
+func DeleteAppPassword(c *gin.Context) {
+	deleteAPIToken(c, false)
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/base64"
 	"image/png"
-	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/captcha"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
+	"github.com/alist-org/alist/v3/internal/login"
+	"github.com/alist-org/alist/v3/internal/mfa"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -14,16 +16,54 @@ import (
 	"github.com/pquerna/otp/totp"
 )
 
-var loginCache = cache.NewMemCache[int]()
-var (
-	defaultDuration = time.Minute * 5
-	defaultTimes    = 5
-)
-
 type LoginReq struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password"`
 	OtpCode  string `json:"otp_code"`
+	Captcha  string `json:"captcha"` // the response of the captcha, required after some failures
+}
+
+// checkLogin rejects the login if the ip or the account is locked, or the captcha is required but not solved
+func checkLogin(c *gin.Context, req *LoginReq) bool {
+	ip := ipfilter.ClientIP(c.Request)
+	if err := ipfilter.Check(ip, nil); err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	if err := login.Check(req.Username, ip); err != nil {
+		common.ErrorResp(c, err, 429)
+		return false
+	}
+	if login.NeedCaptcha(req.Username, ip) {
+		if err := captcha.Verify(c, req.Captcha, ip); err != nil {
+			common.ErrorResp(c, err, 428)
+			return false
+		}
+	}
+	return true
+}
+
+func loginFailed(c *gin.Context, method, username string, err error) {
+	login.Failed(&model.LoginAttempt{
+		Username:  username,
+		IP:        ipfilter.ClientIP(c.Request),
+		Method:    method,
+		Reason:    err.Error(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
+// checkMfa verifies the second factor of the login with the password, the response is written if it fails
+func checkMfa(c *gin.Context, method string, user *model.User, req *LoginReq) bool {
+	err := mfa.VerifyLogin(user, req.OtpCode)
+	if err == nil {
+		return true
+	}
+	common.ErrorResp(c, err, 402)
+	if req.OtpCode != "" {
+		loginFailed(c, method, req.Username, err)
+	}
+	return false
 }
 
 // Login Deprecated
@@ -48,48 +88,39 @@ func LoginHash(c *gin.Context) {
 }
 
 func loginHash(c *gin.Context, req *LoginReq) {
-	// check count of login
-	ip := c.ClientIP()
-	count, ok := loginCache.Get(ip)
-	if ok && count >= defaultTimes {
-		common.ErrorStrResp(c, "Too many unsuccessful sign-in attempts have been made using an incorrect username or password, Try again later.", 429)
-		loginCache.Expire(ip, defaultDuration)
+	if !checkLogin(c, req) {
 		return
 	}
 	// check username
 	user, err := op.GetUserByName(req.Username)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
-		loginCache.Set(ip, count+1)
+		loginFailed(c, model.LoginMethodPassword, req.Username, err)
 		return
 	}
 	// validate password hash
 	if err := user.ValidatePwdStaticHash(req.Password); err != nil {
 		common.ErrorResp(c, err, 400)
-		loginCache.Set(ip, count+1)
+		loginFailed(c, model.LoginMethodPassword, req.Username, err)
 		return
 	}
-	// check 2FA
-	if user.OtpSecret != "" {
-		if !totp.Validate(req.OtpCode, user.OtpSecret) {
-			common.ErrorStrResp(c, "Invalid 2FA code", 402)
-			loginCache.Set(ip, count+1)
-			return
-		}
+	if !checkMfa(c, model.LoginMethodPassword, user, req) {
+		return
 	}
 	// generate token
-	token, err := common.GenerateToken(user)
+	token, err := common.Login(c, user, model.LoginMethodPassword)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
-	common.SuccessResp(c, gin.H{"token": token})
-	loginCache.Del(ip)
+	common.SuccessResp(c, token)
 }
 
 type UserResp struct {
 	model.User
 	Otp bool `json:"otp"`
+	// MfaRequired is set if the user is required to enroll 2FA by the policy but has not
+	MfaRequired bool `json:"mfa_required"`
 }
 
 // CurrentUser get current user by token
@@ -103,6 +134,7 @@ func CurrentUser(c *gin.Context) {
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
+	userResp.MfaRequired = mfa.Required(user) && !mfa.Enrolled(user)
 	common.SuccessResp(c, userResp)
 }
 
@@ -177,9 +209,56 @@ func Verify2FA(c *gin.Context) {
 	user.OtpSecret = req.Secret
 	if err := op.UpdateUser(user); err != nil {
 		common.ErrorResp(c, err, 500)
-	} else {
-		common.SuccessResp(c)
+		return
+	}
+	codes, err := mfa.GenerateRecoveryCodes(user)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{"recovery_codes": codes})
+}
+
+// GetRecoveryCodes returns the number of unused recovery codes of the current user
+func GetRecoveryCodes(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	remaining, err := mfa.RemainingRecoveryCodes(user)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{"remaining": remaining})
+}
+
+type RegenerateRecoveryCodesReq struct {
+	Password string `json:"password"`
+	OtpCode  string `json:"otp_code"`
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user, the old ones become invalid,
+// the user is authenticated again by the password or the 2FA code
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req RegenerateRecoveryCodesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if !mfa.Enrolled(user) {
+		common.ErrorStrResp(c, "2FA is not enabled", 400)
+		return
+	}
+	if !(user.OtpSecret != "" && totp.Validate(req.OtpCode, user.OtpSecret)) &&
+		(req.Password == "" || user.ValidateRawPassword(req.Password) != nil) {
+		common.ErrorStrResp(c, "password or 2FA code is required", 403)
+		return
+	}
+	codes, err := mfa.GenerateRecoveryCodes(user)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
 	}
+	common.SuccessResp(c, gin.H{"recovery_codes": codes})
 }
 
 func LogOut(c *gin.Context) {
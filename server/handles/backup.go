@@ -0,0 +1,78 @@
+package handles
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ExportBackupReq struct {
+	Password string `json:"password" form:"password"`
+	// export without a password, the secrets in the archive are in plaintext
+	Plaintext bool `json:"plaintext" form:"plaintext"`
+}
+
+// ExportBackup downloads the config as a json archive, encrypted by the password unless plaintext is set
+func ExportBackup(c *gin.Context) {
+	var req ExportBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	a, err := backup.Export()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	var data []byte
+	if req.Plaintext && req.Password == "" {
+		data, err = a.MarshalPlaintext()
+	} else {
+		data, err = a.Marshal(req.Password)
+	}
+	if errors.Is(err, backup.ErrPasswordRequired) {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="alist-backup-%s.json"`, time.Now().Format("20060102150405")))
+	c.Data(200, "application/json", data)
+}
+
+// ImportBackup restores the config from an archive uploaded as the file field of a multipart form
+func ImportBackup(c *gin.Context) {
+	f, err := c.FormFile("file")
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	file, err := f.Open()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	a, err := backup.Unmarshal(data, c.PostForm("password"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := backup.Import(c, a, backup.Mode(c.PostForm("mode")), true); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
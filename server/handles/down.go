@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
@@ -31,7 +32,7 @@ func Down(c *gin.Context) {
 		return
 	} else {
 		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
-			IP:      c.ClientIP(),
+			IP:      ipfilter.ClientIP(c.Request),
 			Header:  c.Request.Header,
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -303,7 +304,7 @@ func FsGet(c *gin.Context) {
 			} else {
 				// if storage is not proxy, use raw url by fs.Link
 				link, _, err := fs.Link(c, reqPath, model.LinkArgs{
-					IP:      c.ClientIP(),
+					IP:      ipfilter.ClientIP(c.Request),
 					Header:  c.Request.Header,
 					HttpReq: c.Request,
 				})
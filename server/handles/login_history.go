@@ -0,0 +1,85 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/login"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type LoginHistoryReq struct {
+	model.PageReq
+	Username string `json:"username" form:"username"`
+	IP       string `json:"ip" form:"ip"`
+	Success  string `json:"success" form:"success"` // true or false, empty for both
+}
+
+func listLoginHistory(c *gin.Context, username string) {
+	var req LoginHistoryReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if username == "" {
+		username = req.Username
+	}
+	var success *bool
+	if req.Success != "" {
+		b, err := strconv.ParseBool(req.Success)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		success = &b
+	}
+	attempts, total, err := login.History(username, req.IP, success, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: attempts,
+		Total:   total,
+	})
+}
+
+// ListLoginHistory lists the login attempts of all users
+func ListLoginHistory(c *gin.Context) {
+	listLoginHistory(c, "")
+}
+
+// ListMyLoginHistory lists the login attempts of the current user
+func ListMyLoginHistory(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	listLoginHistory(c, user.Username)
+}
+
+// ListLoginLocks lists the ips and accounts having failed to login
+func ListLoginLocks(c *gin.Context) {
+	locks, err := login.Locks()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, locks)
+}
+
+type UnlockLoginReq struct {
+	Key string `json:"key" binding:"required"` // ip:<ip> or user:<username>
+}
+
+func UnlockLogin(c *gin.Context) {
+	var req UnlockLoginReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := login.Unlock(req.Key); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 type SetAria2Req struct {
@@ -110,10 +112,17 @@ func SetTransmission(c *gin.Context) {
 }
 
 type AddOfflineDownloadReq struct {
-	Urls         []string `json:"urls"`
-	Path         string   `json:"path"`
-	Tool         string   `json:"tool"`
-	DeletePolicy string   `json:"delete_policy"`
+	Urls         []string         `json:"urls"`
+	Path         string           `json:"path"`
+	Tool         string           `json:"tool"`
+	DeletePolicy string           `json:"delete_policy"`
+	Format       string           `json:"format"`
+	Rename       string           `json:"rename"`
+	Rules        []tool.RouteRule `json:"rules"`
+	SeedTime     *int             `json:"seed_time"`
+	SeedRatio    float64          `json:"seed_ratio"`
+	FileFilter   string           `json:"file_filter"`
+	Extract      bool             `json:"extract"`
 }
 
 func AddOfflineDownload(c *gin.Context) {
@@ -133,6 +142,11 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	rules, err := joinRulePaths(user, req.Rules)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
 	var tasks []task.TaskInfoWithCreator
 	for _, url := range req.Urls {
 		t, err := tool.AddURL(c, &tool.AddURLArgs{
@@ -140,6 +154,13 @@ func AddOfflineDownload(c *gin.Context) {
 			DstDirPath:   reqPath,
 			Tool:         req.Tool,
 			DeletePolicy: tool.DeletePolicy(req.DeletePolicy),
+			Format:       req.Format,
+			Rename:       req.Rename,
+			Rules:        rules,
+			SeedTime:     req.SeedTime,
+			SeedRatio:    req.SeedRatio,
+			FileFilter:   req.FileFilter,
+			Extract:      req.Extract,
 		})
 		if err != nil {
 			common.ErrorResp(c, err, 500)
@@ -151,3 +172,123 @@ func AddOfflineDownload(c *gin.Context) {
 		"tasks": getTaskInfos(tasks),
 	})
 }
+
+type SetYtdlpReq struct {
+	Path          string `json:"path" form:"path"`
+	DefaultFormat string `json:"default_format" form:"default_format"`
+	Args          string `json:"args" form:"args"`
+}
+
+func SetYtdlp(c *gin.Context) {
+	var req SetYtdlpReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items := []model.SettingItem{
+		{Key: conf.YtdlpPath, Value: req.Path, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtdlpDefaultFormat, Value: req.DefaultFormat, Type: conf.TypeString, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+		{Key: conf.YtdlpArgs, Value: req.Args, Type: conf.TypeText, Group: model.OFFLINE_DOWNLOAD, Flag: model.PRIVATE},
+	}
+	if err := op.SaveSettingItems(items); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	_tool, err := tool.Tools.Get("yt-dlp")
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	version, err := _tool.Init()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, version)
+}
+
+type AddOfflineDownloadBatchReq struct {
+	AddOfflineDownloadReq
+	// BatchFormat is the format of Content: text, csv or json
+	BatchFormat tool.BatchFormat `json:"batch_format"`
+	Content     string           `json:"content"`
+}
+
+// AddOfflineDownloadBatch imports a list of urls, each line may override the path, tool,
+// rename template and delete policy given in the request
+func AddOfflineDownloadBatch(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if !user.CanAddOfflineDownloadTasks() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+
+	var req AddOfflineDownloadBatchReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	entries, err := tool.ParseBatch(req.BatchFormat, req.Content)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	for _, url := range req.Urls {
+		entries = append(entries, tool.BatchEntry{URL: url})
+	}
+	rules, err := joinRulePaths(user, req.Rules)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	// check all the entries before adding any task, so a batch is added as a whole or not at all
+	args := make([]*tool.AddURLArgs, 0, len(entries))
+	for _, entry := range entries {
+		arg := &tool.AddURLArgs{
+			URL:          entry.URL,
+			Tool:         utils.GetNoneEmpty(entry.Tool, req.Tool),
+			DeletePolicy: tool.DeletePolicy(utils.GetNoneEmpty(string(entry.DeletePolicy), req.DeletePolicy)),
+			Format:       req.Format,
+			Rename:       utils.GetNoneEmpty(entry.Rename, req.Rename),
+			Rules:        rules,
+			SeedTime:     req.SeedTime,
+			SeedRatio:    req.SeedRatio,
+			FileFilter:   req.FileFilter,
+			Extract:      req.Extract,
+		}
+		arg.DstDirPath, err = user.JoinPath(utils.GetNoneEmpty(entry.DstDirPath, req.Path))
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		if err = tool.CheckURLArgs(c, arg); err != nil {
+			common.ErrorResp(c, errors.WithMessagef(err, "invalid %s", arg.URL), 400)
+			return
+		}
+		args = append(args, arg)
+	}
+	var tasks []task.TaskInfoWithCreator
+	for _, arg := range args {
+		t, err := tool.AddURL(c, arg)
+		if err != nil {
+			common.ErrorResp(c, errors.WithMessagef(err, "failed to add %s", arg.URL), 500)
+			return
+		}
+		tasks = append(tasks, t)
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(tasks),
+	})
+}
+
+func joinRulePaths(user *model.User, rules []tool.RouteRule) ([]tool.RouteRule, error) {
+	res := make([]tool.RouteRule, 0, len(rules))
+	for _, rule := range rules {
+		dst, err := user.JoinPath(rule.DstDirPath)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, tool.RouteRule{Pattern: rule.Pattern, DstDirPath: dst})
+	}
+	return res, nil
+}
@@ -0,0 +1,277 @@
+package handles
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sso"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/coreos/go-oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// oidcState is kept between the redirect and the callback of a login
+type oidcState struct {
+	Provider string
+	Method   string
+	Verifier string // the pkce code verifier
+}
+
+var oidcStateCache = cache.NewMemCache(cache.WithShards[*oidcState](16))
+
+func oidcConfig(c *gin.Context, p *sso.Provider) (*oauth2.Config, *oidc.Provider, error) {
+	provider, err := oidc.NewProvider(c, p.Endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  common.GetApiUrl(c.Request) + "/api/auth/oidc/callback",
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID, "profile"}, p.Scopes...),
+	}, provider, nil
+}
+
+// OIDCProviders lists the names of the oidc providers for the login page
+func OIDCProviders(c *gin.Context) {
+	providers, err := sso.GetProviders()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, utils.MustSliceConvert(providers, func(p sso.Provider) string {
+		return p.Name
+	}))
+}
+
+// OIDCLoginRedirect redirects to the authorization endpoint of the provider,
+// method is either sso_get_token to login or get_sso_id to bind the current user
+func OIDCLoginRedirect(c *gin.Context) {
+	if !setting.GetBool(conf.SSOLoginEnabled) {
+		common.ErrorStrResp(c, "Single sign-on is not enabled", 403)
+		return
+	}
+	method := c.DefaultQuery("method", "sso_get_token")
+	if !utils.SliceContains([]string{"get_sso_id", "sso_get_token"}, method) {
+		common.ErrorStrResp(c, "invalid method", 400)
+		return
+	}
+	p, err := sso.GetProvider(c.Query("provider"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	oauth2Config, _, err := oidcConfig(c, p)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	state, err := random.SecureString(32)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	s := &oidcState{Provider: p.Name, Method: method}
+	var opts []oauth2.AuthCodeOption
+	if p.PKCE {
+		s.Verifier = oauth2.GenerateVerifier()
+		opts = append(opts, oauth2.S256ChallengeOption(s.Verifier))
+	}
+	oidcStateCache.Set(state, s, cache.WithEx[*oidcState](10*time.Minute))
+	c.Redirect(302, oauth2Config.AuthCodeURL(state, opts...))
+}
+
+func OIDCCallback(c *gin.Context) {
+	if !setting.GetBool(conf.SSOLoginEnabled) {
+		common.ErrorStrResp(c, "Single sign-on is not enabled", 403)
+		return
+	}
+	s, ok := oidcStateCache.GetDel(c.Query("state"))
+	if !ok {
+		common.ErrorStrResp(c, "incorrect or expired state parameter", 400)
+		return
+	}
+	p, err := sso.GetProvider(s.Provider)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	oauth2Config, provider, err := oidcConfig(c, p)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	var opts []oauth2.AuthCodeOption
+	if s.Verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(s.Verifier))
+	}
+	oauth2Token, err := oauth2Config.Exchange(c, c.Query("code"), opts...)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
+	if !ok {
+		common.ErrorStrResp(c, "no id_token found in oauth2 token", 400)
+		return
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.ClientID}).Verify(c, rawIDToken)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	usernameKey := p.UsernameKey
+	if usernameKey == "" {
+		usernameKey = "preferred_username"
+	}
+	username := sso.ClaimString(claims, usernameKey)
+	if username == "" {
+		common.ErrorStrResp(c, "cannot get username from OIDC provider", 400)
+		return
+	}
+	ssoID := p.SsoID(username)
+	if s.Method == "get_sso_id" {
+		ssoResp(c, "sso_id", ssoID)
+		return
+	}
+	user, err := db.GetUserBySSOID(ssoID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) || !p.AutoRegister {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		user, err = oidcRegister(p, username, ssoID, claims)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+	} else if p.Apply(claims, user) {
+		if err := op.UpdateUser(user); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	token, err := common.LoginWith(c, user, &model.Session{
+		Method:      model.LoginMethodOIDC,
+		SSOProvider: p.Name,
+		SSOSubject:  idToken.Subject,
+		SSOSid:      sso.ClaimString(claims, "sid"),
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if setting.GetBool(conf.SSOCompatibilityMode) {
+		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token.Token)
+		return
+	}
+	html := fmt.Sprintf(`<!DOCTYPE html>
+				<head></head>
+				<body>
+				<script>
+				window.opener.postMessage({"token":"%s","refresh_token":"%s"}, %q)
+				window.close()
+				</script>
+				</body>`, token.Token, token.RefreshToken, common.GetSiteOrigin(c.Request))
+	c.Data(200, "text/html; charset=utf-8", []byte(html))
+}
+
+func ssoResp(c *gin.Context, key, value string) {
+	if setting.GetBool(conf.SSOCompatibilityMode) {
+		c.Redirect(302, common.GetApiUrl(c.Request)+"/@manage?"+key+"="+value)
+		return
+	}
+	html := fmt.Sprintf(`<!DOCTYPE html>
+				<head></head>
+				<body>
+				<script>
+				window.opener.postMessage({"%s": "%s"}, %q)
+				window.close()
+				</script>
+				</body>`, key, value, common.GetSiteOrigin(c.Request))
+	c.Data(200, "text/html; charset=utf-8", []byte(html))
+}
+
+func oidcRegister(p *sso.Provider, username, ssoID string, claims map[string]any) (*model.User, error) {
+	if _, err := db.GetUserByName(username); err == nil {
+		username = username + "_" + p.Name
+	}
+	user := &model.User{
+		Username:   username,
+		Password:   random.String(16),
+		Permission: p.DefaultPermission,
+		BasePath:   utils.FixAndCleanPath(p.DefaultDir),
+		SsoID:      ssoID,
+	}
+	p.Apply(claims, user)
+	if err := db.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// OIDCBackchannelLogout logs out the sessions of the provider given by the logout token,
+// see https://openid.net/specs/openid-connect-backchannel-1_0.html
+func OIDCBackchannelLogout(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	p, err := sso.GetProvider(c.Query("provider"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	provider, err := oidc.NewProvider(c, p.Endpoint)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	// the exp claim is optional in logout tokens, iat is checked instead
+	logoutToken, err := provider.Verifier(&oidc.Config{ClientID: p.ClientID, SkipExpiryCheck: true}).
+		Verify(c, c.PostForm("logout_token"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if time.Since(logoutToken.IssuedAt) > 5*time.Minute {
+		common.ErrorStrResp(c, "logout token is expired", 400)
+		return
+	}
+	var claims map[string]any
+	if err := logoutToken.Claims(&claims); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	events, _ := claims["events"].(map[string]any)
+	if _, ok := events[backchannelLogoutEvent]; !ok || claims["nonce"] != nil {
+		common.ErrorStrResp(c, "not a logout token", 400)
+		return
+	}
+	sid := sso.ClaimString(claims, "sid")
+	if sid == "" && logoutToken.Subject == "" {
+		common.ErrorStrResp(c, "logout token has neither sid nor sub", 400)
+		return
+	}
+	if err := op.DeleteSSOSessions(p.Name, logoutToken.Subject, sid); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	c.Status(200)
+}
@@ -0,0 +1,166 @@
+package handles
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/scim"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func scimResp(c *gin.Context, code int, data any) {
+	res, err := utils.Json.Marshal(data)
+	if err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	c.Data(code, "application/scim+json", res)
+}
+
+func scimErrorResp(c *gin.Context, err error) {
+	var e *scim.Error
+	if !errors.As(err, &e) {
+		log.Errorf("scim: %+v", err)
+		e = scim.NewError(http.StatusInternalServerError, "", "%s", err.Error())
+	}
+	res, _ := utils.Json.Marshal(e)
+	c.Data(e.Code(), "application/scim+json", res)
+}
+
+func scimBody(c *gin.Context) (map[string]any, bool) {
+	var r map[string]any
+	if err := c.ShouldBindJSON(&r); err != nil {
+		scimErrorResp(c, scim.NewError(http.StatusBadRequest, "invalidSyntax", "%s", err.Error()))
+		return nil, false
+	}
+	return r, true
+}
+
+func scimPatchBody(c *gin.Context) ([]scim.PatchOperation, bool) {
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimErrorResp(c, scim.NewError(http.StatusBadRequest, "invalidSyntax", "%s", err.Error()))
+		return nil, false
+	}
+	return req.Operations, true
+}
+
+func scimList[T any](c *gin.Context, resources []T, err error) {
+	if err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	startIndex, _ := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	count, _ := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(scim.MaxResults)))
+	res, err := scim.List(resources, c.Query("filter"), startIndex, count)
+	if err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	scimResp(c, http.StatusOK, res)
+}
+
+func scimResult(c *gin.Context, code int, data any, err error) {
+	if err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	scimResp(c, code, data)
+}
+
+func ScimServiceProviderConfig(c *gin.Context) {
+	scimResp(c, http.StatusOK, scim.ServiceProviderConfig())
+}
+
+func ScimListUsers(c *gin.Context) {
+	users, err := scim.Users()
+	scimList(c, users, err)
+}
+
+func ScimGetUser(c *gin.Context) {
+	user, err := scim.GetUser(c.Param("id"))
+	scimResult(c, http.StatusOK, user, err)
+}
+
+func ScimCreateUser(c *gin.Context) {
+	r, ok := scimBody(c)
+	if !ok {
+		return
+	}
+	user, err := scim.CreateUser(r)
+	scimResult(c, http.StatusCreated, user, err)
+}
+
+func ScimReplaceUser(c *gin.Context) {
+	r, ok := scimBody(c)
+	if !ok {
+		return
+	}
+	user, err := scim.ReplaceUser(c.Param("id"), r)
+	scimResult(c, http.StatusOK, user, err)
+}
+
+func ScimPatchUser(c *gin.Context) {
+	ops, ok := scimPatchBody(c)
+	if !ok {
+		return
+	}
+	user, err := scim.PatchUser(c.Param("id"), ops)
+	scimResult(c, http.StatusOK, user, err)
+}
+
+func ScimDeleteUser(c *gin.Context) {
+	if err := scim.DeleteUser(c.Param("id")); err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func ScimListGroups(c *gin.Context) {
+	groups, err := scim.Groups()
+	scimList(c, groups, err)
+}
+
+func ScimGetGroup(c *gin.Context) {
+	group, err := scim.GetGroup(c.Param("id"))
+	scimResult(c, http.StatusOK, group, err)
+}
+
+func ScimCreateGroup(c *gin.Context) {
+	r, ok := scimBody(c)
+	if !ok {
+		return
+	}
+	group, err := scim.CreateGroup(r)
+	scimResult(c, http.StatusCreated, group, err)
+}
+
+func ScimReplaceGroup(c *gin.Context) {
+	r, ok := scimBody(c)
+	if !ok {
+		return
+	}
+	group, err := scim.ReplaceGroup(c.Param("id"), r)
+	scimResult(c, http.StatusOK, group, err)
+}
+
+func ScimPatchGroup(c *gin.Context) {
+	ops, ok := scimPatchBody(c)
+	if !ok {
+		return
+	}
+	group, err := scim.PatchGroup(c.Param("id"), ops)
+	scimResult(c, http.StatusOK, group, err)
+}
+
+func ScimDeleteGroup(c *gin.Context) {
+	if err := scim.DeleteGroup(c.Param("id")); err != nil {
+		scimErrorResp(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
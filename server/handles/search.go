@@ -60,6 +60,11 @@ func Search(c *gin.Context) {
 		if !common.CanAccess(user, meta, path.Join(node.Parent, node.Name), req.Password) {
 			continue
 		}
+		// the index is built regardless of the visibility of the storages
+		if storage, _, err := op.GetStorageAndActualPath(path.Join(node.Parent, node.Name)); err == nil &&
+			!op.CanSeeStorage(user, storage.GetStorage()) {
+			continue
+		}
 		filteredNodes = append(filteredNodes, node)
 	}
 	common.SuccessResp(c, common.PageResp{
@@ -0,0 +1,102 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken issues a new access token, the refresh token can only be used once
+func RefreshToken(c *gin.Context) {
+	var req RefreshTokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	token, err := common.Refresh(req.RefreshToken)
+	if err != nil {
+		common.ErrorResp(c, err, 401)
+		return
+	}
+	common.SuccessResp(c, token)
+}
+
+type SessionResp struct {
+	model.Session
+	Current bool `json:"current"`
+}
+
+// ListSessions list the active sessions of current user
+func ListSessions(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	sessions, err := op.GetSessions(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	current := c.GetString("session_id")
+	common.SuccessResp(c, utils.MustSliceConvert(sessions, func(s model.Session) SessionResp {
+		return SessionResp{Session: s, Current: s.SessionID == current}
+	}))
+}
+
+// RevokeSession logs out a session of current user
+func RevokeSession(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	s, err := op.GetSession(c.Query("session_id"))
+	if err != nil || s.UserID != user.ID {
+		common.ErrorStrResp(c, "session not found", 404)
+		return
+	}
+	if err := op.DeleteSession(s.SessionID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ListUserSessions list the active sessions of the user given by id
+func ListUserSessions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	sessions, err := op.GetSessions(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, sessions)
+}
+
+// AdminRevokeSession logs out any session
+func AdminRevokeSession(c *gin.Context) {
+	if err := op.DeleteSession(c.Query("session_id")); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// LogoutUser logs out all sessions of the user given by id
+func LogoutUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteSessionsByUserId(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
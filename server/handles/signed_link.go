@@ -0,0 +1,157 @@
+package handles
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type CreateSignedLinkReq struct {
+	Path     string   `json:"path" binding:"required"`
+	Password string   `json:"password"`
+	ExpireIn int64    `json:"expire_in"` // in seconds, 0 means never
+	IPs      []string `json:"ips"`
+	Referer  string   `json:"referer"`
+	MaxUses  int      `json:"max_uses"`
+	Remark   string   `json:"remark"`
+}
+
+type SignedLinkResp struct {
+	model.SignedLink
+	URL string `json:"url"`
+}
+
+func CreateSignedLink(c *gin.Context) {
+	var req CreateSignedLinkReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if obj.IsDir() {
+		common.ErrorStrResp(c, "can't sign a folder", 400)
+		return
+	}
+	for _, ip := range req.IPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				common.ErrorStrResp(c, fmt.Sprintf("invalid ip or cidr: %s", ip), 400)
+				return
+			}
+		}
+	}
+	if req.MaxUses < 0 || req.ExpireIn < 0 {
+		common.ErrorStrResp(c, "max_uses and expire_in can't be negative", 400)
+		return
+	}
+	l := &model.SignedLink{
+		UserID:  user.ID,
+		Path:    reqPath,
+		IPs:     strings.Join(req.IPs, ","),
+		Referer: req.Referer,
+		MaxUses: req.MaxUses,
+		Remark:  req.Remark,
+	}
+	if req.ExpireIn > 0 {
+		l.Expire = time.Now().Add(time.Duration(req.ExpireIn) * time.Second).Unix()
+	}
+	if err := op.CreateSignedLink(l); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, toSignedLinkResp(c, *l))
+}
+
+func toSignedLinkResp(c *gin.Context, l model.SignedLink) SignedLinkResp {
+	return SignedLinkResp{
+		SignedLink: l,
+		URL: fmt.Sprintf("%s/d%s?sign=%s&lid=%s",
+			common.GetApiUrl(c.Request),
+			utils.EncodePath(l.Path, true),
+			sign.Scoped(l.Path, l.Key, l.Expire),
+			l.Key),
+	}
+}
+
+// ListSignedLinks list the links of current user, admin gets the links of all users
+func ListSignedLinks(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	var userId uint
+	if !user.IsAdmin() {
+		userId = user.ID
+	}
+	links, total, err := op.GetSignedLinks(userId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: utils.MustSliceConvert(links, func(l model.SignedLink) SignedLinkResp {
+			return toSignedLinkResp(c, l)
+		}),
+		Total: total,
+	})
+}
+
+// DeleteSignedLink revokes a link, users can only revoke their own links except admin
+func DeleteSignedLink(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	l, err := op.GetSignedLinkById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if l.UserID != user.ID && !user.IsAdmin() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	if err := op.DeleteSignedLinkById(l.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -245,10 +245,10 @@ func OIDCLoginCallback(c *gin.Context) {
 				<head></head>
 				<body>
 				<script>
-				window.opener.postMessage({"sso_id": "%s"}, "*")
+				window.opener.postMessage({"sso_id": "%s"}, %q)
 				window.close()
 				</script>
-				</body>`, userID)
+				</body>`, userID, common.GetSiteOrigin(c.Request))
 		c.Data(200, "text/html; charset=utf-8", []byte(html))
 		return
 	}
@@ -260,22 +260,23 @@ func OIDCLoginCallback(c *gin.Context) {
 				common.ErrorResp(c, err, 400)
 			}
 		}
-		token, err := common.GenerateToken(user)
+		token, err := common.Login(c, user, model.LoginMethodSSO)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
+			return
 		}
 		if useCompatibility {
-			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
+			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token.Token)
 			return
 		}
 		html := fmt.Sprintf(`<!DOCTYPE html>
 				<head></head>
 				<body>
 				<script>
-				window.opener.postMessage({"token":"%s"}, "*")
+				window.opener.postMessage({"token":"%s","refresh_token":"%s"}, %q)
 				window.close()
 				</script>
-				</body>`, token)
+				</body>`, token.Token, token.RefreshToken, common.GetSiteOrigin(c.Request))
 		c.Data(200, "text/html; charset=utf-8", []byte(html))
 		return
 	}
@@ -410,10 +411,10 @@ func SSOLoginCallback(c *gin.Context) {
 				<head></head>
 				<body>
 				<script>
-				window.opener.postMessage({"sso_id": "%s"}, "*")
+				window.opener.postMessage({"sso_id": "%s"}, %q)
 				window.close()
 				</script>
-				</body>`, userID)
+				</body>`, userID, common.GetSiteOrigin(c.Request))
 		c.Data(200, "text/html; charset=utf-8", []byte(html))
 		return
 	}
@@ -426,21 +427,22 @@ func SSOLoginCallback(c *gin.Context) {
 			return
 		}
 	}
-	token, err := common.GenerateToken(user)
+	token, err := common.Login(c, user, model.LoginMethodSSO)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
+		return
 	}
 	if usecompatibility {
-		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
+		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token.Token)
 		return
 	}
 	html := fmt.Sprintf(`<!DOCTYPE html>
 							<head></head>
 							<body>
 							<script>
-							window.opener.postMessage({"token":"%s"}, "*")
+							window.opener.postMessage({"token":"%s","refresh_token":"%s"}, %q)
 							window.close()
 							</script>
-							</body>`, token)
+							</body>`, token.Token, token.RefreshToken, common.GetSiteOrigin(c.Request))
 	c.Data(200, "text/html; charset=utf-8", []byte(html))
 }
@@ -0,0 +1,66 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/health"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListStorageHealth lists the current health of the enabled storages
+func ListStorageHealth(c *gin.Context) {
+	common.SuccessResp(c, health.States())
+}
+
+type StorageChecksReq struct {
+	model.PageReq
+	StorageID uint `json:"storage_id" form:"storage_id"` // 0 for all storages
+}
+
+// ListStorageChecks lists the check history of the storages
+func ListStorageChecks(c *gin.Context) {
+	var req StorageChecksReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	checks, total, err := health.History(req.StorageID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: checks,
+		Total:   total,
+	})
+}
+
+// CheckStorage checks the storage immediately
+func CheckStorage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	storage, err := op.GetStorageByMountPath(s.MountPath)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := health.Check(c, storage); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
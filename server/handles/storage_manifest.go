@@ -0,0 +1,46 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/manifest"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ApplyStorageManifestReq struct {
+	Manifest string `json:"manifest" binding:"required"` // in yaml or json
+	DryRun   bool   `json:"dry_run"`
+	Prune    bool   `json:"prune"`
+}
+
+// ApplyStorageManifest applies the manifest of storages, or previews the changes if dry run
+func ApplyStorageManifest(c *gin.Context) {
+	var req ApplyStorageManifestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	m, err := manifest.Parse([]byte(req.Manifest))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.DryRun {
+		changes, err := manifest.Plan(m, req.Prune)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		common.SuccessResp(c, changes)
+		return
+	}
+	changes, err := manifest.Apply(c, m, req.Prune, true)
+	if err != nil {
+		if changes == nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		common.ErrorWithDataResp(c, err, 500, changes, true)
+		return
+	}
+	common.SuccessResp(c, changes)
+}
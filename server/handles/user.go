@@ -3,6 +3,7 @@ package handles
 import (
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -39,6 +40,10 @@ func CreateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin or guest user can not be created", 400, true)
 		return
 	}
+	if err := validateIPs(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	req.SetPassword(req.Password)
 	req.Password = ""
 	req.Authn = "[]"
@@ -49,6 +54,14 @@ func CreateUser(c *gin.Context) {
 	}
 }
 
+func validateIPs(u *model.User) error {
+	if _, err := ipfilter.ParseList(u.AllowedIPs); err != nil {
+		return err
+	}
+	_, err := ipfilter.ParseList(u.DeniedIPs)
+	return err
+}
+
 func UpdateUser(c *gin.Context) {
 	var req model.User
 	if err := c.ShouldBind(&req); err != nil {
@@ -64,6 +77,10 @@ func UpdateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "role can not be changed", 400)
 		return
 	}
+	if err := validateIPs(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	if req.Password == "" {
 		req.PwdHash = user.PwdHash
 		req.Salt = user.Salt
@@ -74,6 +91,9 @@ func UpdateUser(c *gin.Context) {
 	if req.OtpSecret == "" {
 		req.OtpSecret = user.OtpSecret
 	}
+	if req.MfaEnrollBy == 0 {
+		req.MfaEnrollBy = user.MfaEnrollBy
+	}
 	if req.Disabled && req.IsAdmin() {
 		common.ErrorStrResp(c, "admin user can not be disabled", 400)
 		return
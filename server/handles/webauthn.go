@@ -111,12 +111,12 @@ func FinishAuthnLogin(c *gin.Context) {
 		return
 	}
 
-	token, err := common.GenerateToken(user)
+	token, err := common.Login(c, user, model.LoginMethodWebauthn)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
-	common.SuccessResp(c, gin.H{"token": token})
+	common.SuccessResp(c, token)
 }
 
 func BeginAuthnRegistration(c *gin.Context) {
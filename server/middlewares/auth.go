@@ -2,8 +2,10 @@ package middlewares
 
 import (
 	"crypto/subtle"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -23,11 +25,30 @@ func Auth(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !checkIP(c, admin) {
+			return
+		}
 		c.Set("user", admin)
 		log.Debugf("use admin token: %+v", admin)
 		c.Next()
 		return
 	}
+	if apiToken := strings.TrimPrefix(token, "Bearer "); op.IsAPIToken(apiToken) {
+		user, t, err := op.AuthAPIToken(apiToken)
+		if err != nil {
+			common.ErrorResp(c, err, 401)
+			c.Abort()
+			return
+		}
+		if !checkIP(c, user) {
+			return
+		}
+		c.Set("user", user)
+		c.Set("api_token", t)
+		log.Debugf("use api token %d: %+v", t.ID, user)
+		c.Next()
+		return
+	}
 	if token == "" {
 		guest, err := op.GetGuest()
 		if err != nil {
@@ -40,6 +61,9 @@ func Auth(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !checkIP(c, guest) {
+			return
+		}
 		c.Set("user", guest)
 		log.Debugf("use empty token: %+v", guest)
 		c.Next()
@@ -68,6 +92,12 @@ func Auth(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !checkSession(c, userClaims, user) {
+		return
+	}
+	if !checkIP(c, user) {
+		return
+	}
 	c.Set("user", user)
 	log.Debugf("use login token: %+v", user)
 	c.Next()
@@ -82,6 +112,9 @@ func Authn(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !checkIP(c, admin) {
+			return
+		}
 		c.Set("user", admin)
 		log.Debugf("use admin token: %+v", admin)
 		c.Next()
@@ -94,6 +127,9 @@ func Authn(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !checkIP(c, guest) {
+			return
+		}
 		c.Set("user", guest)
 		log.Debugf("use empty token: %+v", guest)
 		c.Next()
@@ -122,11 +158,40 @@ func Authn(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !checkSession(c, userClaims, user) {
+		return
+	}
+	if !checkIP(c, user) {
+		return
+	}
 	c.Set("user", user)
 	log.Debugf("use login token: %+v", user)
 	c.Next()
 }
 
+// checkIP rejects the request if the ip of the client is denied globally or for the user
+func checkIP(c *gin.Context, user *model.User) bool {
+	if err := ipfilter.Check(ipfilter.ClientIP(c.Request), user); err != nil {
+		common.ErrorResp(c, err, 403)
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// checkSession makes sure the session of the token belongs to the user and records its activity
+func checkSession(c *gin.Context, claims *common.UserClaims, user *model.User) bool {
+	session, err := op.GetSession(claims.SessionID)
+	if err != nil || session.UserID != user.ID {
+		common.ErrorStrResp(c, "Session is revoked, login please", 401)
+		c.Abort()
+		return false
+	}
+	op.TouchSession(session, ipfilter.ClientIP(c.Request))
+	c.Set("session_id", session.SessionID)
+	return true
+}
+
 func AuthNotGuest(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	if user.IsGuest() {
@@ -146,3 +211,26 @@ func AuthAdmin(c *gin.Context) {
 		c.Next()
 	}
 }
+
+// APITokenScope rejects requests authorized by an api token which is not granted the scope,
+// requests authorized by other means are not affected
+func APITokenScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t, ok := c.Get("api_token"); ok && !t.(*model.APIToken).HasScope(scope) {
+			common.ErrorStrResp(c, "The api token is not granted the scope: "+scope, 403)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// NoAPIToken rejects requests authorized by an api token, used for account management
+func NoAPIToken(c *gin.Context) {
+	if _, ok := c.Get("api_token"); ok {
+		common.ErrorStrResp(c, "Not allowed with an api token", 403)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -1,12 +1,14 @@
 package middlewares
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/setting"
 
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/sign"
@@ -17,6 +19,9 @@ import (
 )
 
 func Down(c *gin.Context) {
+	if !checkIP(c, nil) {
+		return
+	}
 	rawPath := parsePath(c.Param("path"))
 	c.Set("path", rawPath)
 	meta, err := op.GetNearestMeta(rawPath)
@@ -27,19 +32,40 @@ func Down(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	// verify sign
-	if needSign(meta, rawPath) {
-		s := c.Query("sign")
-		err = sign.Verify(rawPath, strings.TrimSuffix(s, "/"))
-		if err != nil {
-			common.ErrorResp(c, err, 401)
-			c.Abort()
-			return
-		}
+	s := strings.TrimSuffix(c.Query("sign"), "/")
+	// the visibility of the storages is checked for the owner of a signed link, or the guest.
+	// a valid sign is only given to the users who can see the file, so it is not checked then
+	var user *model.User
+	// verify sign, a signed link is always checked so that its restrictions can't be bypassed
+	if key := c.Query("lid"); key != "" {
+		user, err = verifySignedLink(c, rawPath, key, s)
+	} else if needSign(meta, rawPath) {
+		err = sign.Verify(rawPath, s)
+	} else if s == "" || sign.Verify(rawPath, s) != nil {
+		user, err = op.GetGuest()
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 401)
+		c.Abort()
+		return
+	}
+	if user != nil {
+		c.Set("user", user)
 	}
 	c.Next()
 }
 
+func verifySignedLink(c *gin.Context, rawPath, key, s string) (*model.User, error) {
+	if err := sign.VerifyScoped(rawPath, key, s); err != nil {
+		return nil, err
+	}
+	// a player or a downloader may send many range requests, so a use is spent per client instead of per request
+	ip := ipfilter.ClientIP(c.Request)
+	client := ip + "|" + c.GetHeader("User-Agent")
+	read := c.Request.Method == http.MethodGet
+	return op.CheckSignedLink(key, rawPath, ip, c.GetHeader("Referer"), client, read)
+}
+
 // TODO: implement
 // path maybe contains # ? etc.
 func parsePath(path string) string {
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/mfa"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireMfa blocks the users required to enroll 2FA by the policy once the grace period is over,
+// the admin token is not affected as it cannot enroll. the grace period is started here as well,
+// since the users of api tokens or long-lived sessions may never log in with the password again
+func RequireMfa(c *gin.Context) {
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(setting.GetStr(conf.Token))) == 1 {
+		c.Next()
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	mfa.StartGracePeriod(user)
+	if err := mfa.Check(user); err != nil {
+		common.ErrorResp(c, err, 403)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
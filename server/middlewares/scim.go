@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/scim"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/gin-gonic/gin"
+)
+
+// ScimAuth checks the bearer token of the identity provider against the scim token in the settings
+func ScimAuth(c *gin.Context) {
+	if !setting.GetBool(conf.ScimEnabled) {
+		c.AbortWithStatusJSON(http.StatusNotFound, scim.NewError(http.StatusNotFound, "", "scim is not enabled"))
+		return
+	}
+	expected := setting.GetStr(conf.ScimToken)
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, scim.NewError(http.StatusUnauthorized, "", "invalid scim token"))
+		return
+	}
+	c.Next()
+}
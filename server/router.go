@@ -3,7 +3,9 @@ package server
 import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/handles"
@@ -13,7 +15,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// setTrustedProxies makes the forwarded headers only trusted from the configured proxies
+func setTrustedProxies(e *gin.Engine) {
+	if err := ipfilter.SetTrustedProxies(conf.Conf.TrustedProxies); err != nil {
+		utils.Log.Fatalf("invalid trusted proxies: %+v", err)
+	}
+	if err := e.SetTrustedProxies(conf.Conf.TrustedProxies); err != nil {
+		utils.Log.Fatalf("invalid trusted proxies: %+v", err)
+	}
+}
+
 func Init(e *gin.Engine) {
+	setTrustedProxies(e)
 	if !utils.SliceContains([]string{"", "/"}, conf.URL.Path) {
 		e.GET("/", func(c *gin.Context) {
 			c.Redirect(302, conf.URL.Path)
@@ -43,6 +56,8 @@ func Init(e *gin.Engine) {
 	g.HEAD("/d/*path", middlewares.Down, handles.Down)
 	g.HEAD("/p/*path", middlewares.Down, handles.Proxy)
 
+	scim(g.Group("/scim/v2", middlewares.ScimAuth))
+
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
 	webauthn := api.Group("/authn", middlewares.Authn)
@@ -50,17 +65,27 @@ func Init(e *gin.Engine) {
 	api.POST("/auth/login", handles.Login)
 	api.POST("/auth/login/hash", handles.LoginHash)
 	api.POST("/auth/login/ldap", handles.LoginLdap)
+	api.POST("/auth/refresh", handles.RefreshToken)
 	auth.GET("/me", handles.CurrentUser)
-	auth.POST("/me/update", handles.UpdateCurrent)
-	auth.POST("/auth/2fa/generate", handles.Generate2FA)
-	auth.POST("/auth/2fa/verify", handles.Verify2FA)
+	auth.POST("/me/update", middlewares.NoAPIToken, handles.UpdateCurrent)
+	auth.POST("/auth/2fa/generate", middlewares.NoAPIToken, handles.Generate2FA)
+	auth.POST("/auth/2fa/verify", middlewares.NoAPIToken, handles.Verify2FA)
+	auth.GET("/me/recovery_codes", middlewares.AuthNotGuest, middlewares.NoAPIToken, handles.GetRecoveryCodes)
+	auth.POST("/me/recovery_codes/regenerate", middlewares.AuthNotGuest, middlewares.NoAPIToken, handles.RegenerateRecoveryCodes)
 	auth.GET("/auth/logout", handles.LogOut)
+	auth.GET("/me/sessions", middlewares.AuthNotGuest, middlewares.NoAPIToken, handles.ListSessions)
+	auth.POST("/me/sessions/revoke", middlewares.AuthNotGuest, middlewares.NoAPIToken, handles.RevokeSession)
+	auth.GET("/me/login_history", middlewares.AuthNotGuest, middlewares.NoAPIToken, handles.ListMyLoginHistory)
 
 	// auth
 	api.GET("/auth/sso", handles.SSOLoginRedirect)
 	api.GET("/auth/sso_callback", handles.SSOLoginCallback)
 	api.GET("/auth/get_sso_id", handles.SSOLoginCallback)
 	api.GET("/auth/sso_get_token", handles.SSOLoginCallback)
+	api.GET("/auth/oidc/providers", handles.OIDCProviders)
+	api.GET("/auth/oidc/login", handles.OIDCLoginRedirect)
+	api.GET("/auth/oidc/callback", handles.OIDCCallback)
+	api.POST("/auth/oidc/backchannel_logout", handles.OIDCBackchannelLogout)
 
 	// webauthn
 	webauthn.GET("/webauthn_begin_registration", handles.BeginAuthnRegistration)
@@ -75,9 +100,13 @@ func Init(e *gin.Engine) {
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 
-	_fs(auth.Group("/fs"))
-	_task(auth.Group("/task", middlewares.AuthNotGuest))
-	admin(auth.Group("/admin", middlewares.AuthAdmin))
+	// users not enrolling 2FA required by the policy can only access the endpoints above
+	_fs(auth.Group("/fs", middlewares.RequireMfa))
+	signedLink(auth.Group("/signed_link", middlewares.AuthNotGuest, middlewares.RequireMfa, middlewares.APITokenScope(model.ScopeFsRead)))
+	apiToken(auth.Group("/me/api_token", middlewares.AuthNotGuest, middlewares.RequireMfa, middlewares.NoAPIToken))
+	appPassword(auth.Group("/me/app_password", middlewares.AuthNotGuest, middlewares.RequireMfa, middlewares.NoAPIToken))
+	_task(auth.Group("/task", middlewares.AuthNotGuest, middlewares.RequireMfa, middlewares.APITokenScope(model.ScopeTasks)))
+	admin(auth.Group("/admin", middlewares.AuthAdmin, middlewares.RequireMfa, middlewares.APITokenScope(model.ScopeAdmin)))
 	if flags.Debug || flags.Dev {
 		debug(g.Group("/debug"))
 	}
@@ -102,6 +131,10 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.GET("/sessions", handles.ListUserSessions)
+	user.POST("/revoke_session", handles.AdminRevokeSession)
+	user.POST("/logout", handles.LogoutUser)
+	user.POST("/ldap_sync", handles.SyncLdap)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -112,6 +145,10 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/health", handles.ListStorageHealth)
+	storage.GET("/checks", handles.ListStorageChecks)
+	storage.POST("/check", handles.CheckStorage)
+	storage.POST("/apply", handles.ApplyStorageManifest)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
@@ -127,6 +164,29 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_aria2", handles.SetAria2)
 	setting.POST("/set_qbit", handles.SetQbittorrent)
 	setting.POST("/set_transmission", handles.SetTransmission)
+	setting.POST("/set_ytdlp", handles.SetYtdlp)
+
+	feed := g.Group("/feed")
+	feed.GET("/list", handles.ListFeeds)
+	feed.GET("/get", handles.GetFeed)
+	feed.POST("/create", handles.CreateFeed)
+	feed.POST("/update", handles.UpdateFeed)
+	feed.POST("/delete", handles.DeleteFeed)
+	feed.POST("/refresh", handles.RefreshFeed)
+	feed.GET("/items", handles.ListFeedItems)
+
+	backup := g.Group("/backup")
+	backup.POST("/export", handles.ExportBackup)
+	backup.POST("/import", handles.ImportBackup)
+
+	loginGroup := g.Group("/login")
+	loginGroup.GET("/history", handles.ListLoginHistory)
+	loginGroup.GET("/locks", handles.ListLoginLocks)
+	loginGroup.POST("/unlock", handles.UnlockLogin)
+
+	apiToken := g.Group("/api_token")
+	apiToken.GET("/list", handles.ListAllAPITokens)
+	apiToken.POST("/delete", handles.AdminDeleteAPIToken)
 
 	// retain /admin/task API to ensure compatibility with legacy automation scripts
 	_task(g.Group("/task"))
@@ -144,27 +204,64 @@ func admin(g *gin.RouterGroup) {
 }
 
 func _fs(g *gin.RouterGroup) {
-	g.Any("/list", handles.FsList)
-	g.Any("/search", middlewares.SearchIndex, handles.Search)
-	g.Any("/get", handles.FsGet)
-	g.Any("/other", handles.FsOther)
-	g.Any("/dirs", handles.FsDirs)
-	g.POST("/mkdir", handles.FsMkdir)
-	g.POST("/rename", handles.FsRename)
-	g.POST("/batch_rename", handles.FsBatchRename)
-	g.POST("/regex_rename", handles.FsRegexRename)
-	g.POST("/move", handles.FsMove)
-	g.POST("/recursive_move", handles.FsRecursiveMove)
-	g.POST("/copy", handles.FsCopy)
-	g.POST("/remove", handles.FsRemove)
-	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
-	g.PUT("/put", middlewares.FsUp, handles.FsStream)
-	g.PUT("/form", middlewares.FsUp, handles.FsForm)
-	g.POST("/link", middlewares.AuthAdmin, handles.Link)
-	// g.POST("/add_aria2", handles.AddOfflineDownload)
-	// g.POST("/add_qbit", handles.AddQbittorrent)
-	// g.POST("/add_transmission", handles.SetTransmission)
-	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	r := g.Group("", middlewares.APITokenScope(model.ScopeFsRead))
+	r.Any("/list", handles.FsList)
+	r.Any("/search", middlewares.SearchIndex, handles.Search)
+	r.Any("/get", handles.FsGet)
+	r.Any("/other", handles.FsOther)
+	r.Any("/dirs", handles.FsDirs)
+	r.POST("/link", middlewares.AuthAdmin, handles.Link)
+	w := g.Group("", middlewares.APITokenScope(model.ScopeFsWrite))
+	w.POST("/mkdir", handles.FsMkdir)
+	w.POST("/rename", handles.FsRename)
+	w.POST("/batch_rename", handles.FsBatchRename)
+	w.POST("/regex_rename", handles.FsRegexRename)
+	w.POST("/move", handles.FsMove)
+	w.POST("/recursive_move", handles.FsRecursiveMove)
+	w.POST("/copy", handles.FsCopy)
+	w.POST("/remove", handles.FsRemove)
+	w.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
+	w.PUT("/put", middlewares.FsUp, handles.FsStream)
+	w.PUT("/form", middlewares.FsUp, handles.FsForm)
+	// w.POST("/add_aria2", handles.AddOfflineDownload)
+	// w.POST("/add_qbit", handles.AddQbittorrent)
+	// w.POST("/add_transmission", handles.SetTransmission)
+	w.POST("/add_offline_download", handles.AddOfflineDownload)
+	w.POST("/add_offline_download_batch", handles.AddOfflineDownloadBatch)
+}
+
+func signedLink(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListSignedLinks)
+	g.POST("/create", handles.CreateSignedLink)
+	g.POST("/delete", handles.DeleteSignedLink)
+}
+
+func apiToken(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListAPITokens)
+	g.POST("/create", handles.CreateAPIToken)
+	g.POST("/delete", handles.DeleteAPIToken)
+}
+
+func scim(g *gin.RouterGroup) {
+	g.GET("/ServiceProviderConfig", handles.ScimServiceProviderConfig)
+	g.GET("/Users", handles.ScimListUsers)
+	g.POST("/Users", handles.ScimCreateUser)
+	g.GET("/Users/:id", handles.ScimGetUser)
+	g.PUT("/Users/:id", handles.ScimReplaceUser)
+	g.PATCH("/Users/:id", handles.ScimPatchUser)
+	g.DELETE("/Users/:id", handles.ScimDeleteUser)
+	g.GET("/Groups", handles.ScimListGroups)
+	g.POST("/Groups", handles.ScimCreateGroup)
+	g.GET("/Groups/:id", handles.ScimGetGroup)
+	g.PUT("/Groups/:id", handles.ScimReplaceGroup)
+	g.PATCH("/Groups/:id", handles.ScimPatchGroup)
+	g.DELETE("/Groups/:id", handles.ScimDeleteGroup)
+}
+
+func appPassword(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListAppPasswords)
+	g.POST("/create", handles.CreateAppPassword)
+	g.POST("/delete", handles.DeleteAppPassword)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -181,6 +278,7 @@ func Cors(r *gin.Engine) {
 }
 
 func InitS3(e *gin.Engine) {
+	setTrustedProxies(e)
 	Cors(e)
 	S3Server(e.Group("/"))
 }
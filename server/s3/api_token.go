@@ -0,0 +1,258 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
+	"github.com/alist-org/alist/v3/internal/login"
+	"github.com/alist-org/alist/v3/internal/mfa"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/gofakes3"
+	"github.com/alist-org/gofakes3/signature"
+)
+
+var credentialRegexp = regexp.MustCompile(`Credential=([^/,\s]+)/`)
+
+// requestAccessKey returns the access key id of the v4 or v2 signed request
+func requestAccessKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if m := credentialRegexp.FindStringSubmatch(auth); m != nil {
+			return m[1]
+		}
+		if strings.HasPrefix(auth, "AWS ") {
+			key, _, _ := strings.Cut(strings.TrimPrefix(auth, "AWS "), ":")
+			return key
+		}
+		return ""
+	}
+	if cred := r.URL.Query().Get("X-Amz-Credential"); cred != "" {
+		key, _, _ := strings.Cut(cred, "/")
+		return key
+	}
+	return r.URL.Query().Get("AWSAccessKeyId")
+}
+
+// withAPIToken serves requests signed with the key of an api token by tokenHandler,
+// which verifies the signature with the derived secret, other requests are served by h as before
+func withAPIToken(h, tokenHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := ipfilter.ClientIP(r)
+		if err := ipfilter.Check(ip, nil); err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		key := requestAccessKey(r)
+		if key == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if err := login.Check("", ip); err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		if key == setting.GetStr(conf.S3AccessKeyId) {
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(sw, r)
+			recordLogin(r, "", sw.status)
+			return
+		}
+		t, err := op.GetAPITokenByKey(key)
+		if err != nil {
+			loginFailed(r, "", err)
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		user, err := op.GetAPITokenUser(t)
+		if err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		if err := login.Check(user.Username, ip); err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		if err := ipfilter.Check(ip, user); err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		mfa.StartGracePeriod(user)
+		if err := mfa.Check(user); err != nil {
+			writeAccessDenied(w, err.Error())
+			return
+		}
+		if err := checkAPITokenAccess(r, user, t); err != "" {
+			writeAccessDenied(w, err)
+			return
+		}
+		// the key is only stored while the request is served, so a revoked or expired token can't sign
+		tokenKeys.acquire(t.Key, op.APITokenS3Secret(t))
+		defer tokenKeys.release(t.Key)
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		tokenHandler.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), "user", user)))
+		recordLogin(r, user.Username, sw.status)
+	})
+}
+
+// keyStore keeps the keys of the api tokens in the credentials of gofakes3 during their requests,
+// the credentials are global, so the other keys are kept as the base
+type keyStore struct {
+	mu   sync.Mutex
+	base map[string]string
+	keys map[string]string
+	refs map[string]int
+}
+
+var tokenKeys = &keyStore{
+	base: map[string]string{},
+	keys: map[string]string{},
+	refs: map[string]int{},
+}
+
+// addBase adds the keys of a faker, which are stored by itself
+func (s *keyStore) addBase(pairs map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range pairs {
+		s.base[k] = v
+	}
+}
+
+func (s *keyStore) acquire(key, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs[key]++
+	s.keys[key] = secret
+	signature.StoreKeys(map[string]string{key: secret})
+}
+
+func (s *keyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs[key]--; s.refs[key] > 0 {
+		return
+	}
+	delete(s.refs, key)
+	delete(s.keys, key)
+	if _, ok := s.base[key]; ok {
+		return
+	}
+	// gofakes3 only removes the keys absent from the given ones
+	pairs := make(map[string]string, len(s.base)+len(s.keys))
+	for k, v := range s.base {
+		pairs[k] = v
+	}
+	for k, v := range s.keys {
+		pairs[k] = v
+	}
+	signature.ReloadKeys(pairs)
+}
+
+// checkPermission checks the permission of the user of an api token for a write operation,
+// the requests signed with the access key of the settings have no user
+func checkPermission(ctx context.Context, perm func(*model.User) bool) error {
+	if user, ok := ctx.Value("user").(*model.User); ok && !perm(user) {
+		return gofakes3.ErrorMessage("AccessDenied", "permission denied")
+	}
+	return nil
+}
+
+// statusWriter records the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// recordLogin records the authentication of the request by the status of its response,
+// the signature is verified by gofakes3, which responds 403 if it does not match
+func recordLogin(r *http.Request, username string, status int) {
+	switch {
+	case status == http.StatusForbidden:
+		loginFailed(r, username, errors.New("signature does not match"))
+	case status < http.StatusBadRequest && login.HasFailures(username, ipfilter.ClientIP(r)):
+		// every request is authenticated, only the failures are forgotten
+		login.Succeeded(&model.LoginAttempt{
+			Username:  username,
+			IP:        ipfilter.ClientIP(r),
+			Method:    model.LoginMethodS3,
+			UserAgent: r.UserAgent(),
+		})
+	}
+}
+
+func loginFailed(r *http.Request, username string, err error) {
+	login.Failed(&model.LoginAttempt{
+		Username:  username,
+		IP:        ipfilter.ClientIP(r),
+		Method:    model.LoginMethodS3,
+		Reason:    err.Error(),
+		UserAgent: r.UserAgent(),
+	})
+}
+
+// checkAPITokenAccess checks the scope of the token and that the paths of the request are under the base path of the user
+func checkAPITokenAccess(r *http.Request, user *model.User, t *model.APIToken) string {
+	write := r.Method == http.MethodPut || r.Method == http.MethodPost || r.Method == http.MethodDelete
+	scope := model.ScopeFsRead
+	if write {
+		scope = model.ScopeFsWrite
+	}
+	if !t.HasScope(scope) {
+		return "the api token is not granted the scope: " + scope
+	}
+	paths := []string{r.URL.Path}
+	if src := r.Header.Get("X-Amz-Copy-Source"); src != "" {
+		if s, err := url.PathUnescape(src); err == nil {
+			src = s
+		}
+		paths = append(paths, "/"+strings.TrimPrefix(src, "/"))
+	}
+	for i, p := range paths {
+		bucketName, object, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+		if bucketName == "" {
+			// list buckets
+			continue
+		}
+		b, err := getBucketByName(bucketName)
+		if err != nil {
+			return err.Error()
+		}
+		if i == 0 && object == "" && !write {
+			// list bucket, the directory of the prefix is listed
+			object = r.URL.Query().Get("prefix")
+			if !strings.HasSuffix(object, "/") {
+				object = path.Dir(object)
+			}
+		}
+		if !utils.IsSubPath(user.BasePath, path.Join(b.Path, object)) {
+			return "the path is out of the scope of the api token"
+		}
+	}
+	return ""
+}
+
+func writeAccessDenied(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusForbidden)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: "AccessDenied", Message: msg})
+}
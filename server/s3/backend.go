@@ -208,6 +208,9 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 			//remoteClosers.Add(remoteLink.MFile)
 			//keep reuse same MFile and close at last.
 			remoteClosers.Add(link.MFile)
+			if length >= 0 {
+				return io.NopCloser(io.LimitReader(link.MFile, length)), nil
+			}
 			return io.NopCloser(link.MFile), nil
 		}
 		return nil, errs.NotSupport
@@ -261,6 +264,9 @@ func (b *s3Backend) PutObject(
 	meta map[string]string,
 	input io.Reader, size int64,
 ) (result gofakes3.PutObjectResult, err error) {
+	if err := checkPermission(ctx, (*model.User).CanWrite); err != nil {
+		return result, err
+	}
 	bucket, err := getBucketByName(bucketName)
 	if err != nil {
 		return result, err
@@ -365,6 +371,9 @@ func (b *s3Backend) DeleteObject(ctx context.Context, bucketName, objectName str
 
 // deleteObject deletes the object from the filesystem.
 func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName string) error {
+	if err := checkPermission(ctx, (*model.User).CanRemove); err != nil {
+		return err
+	}
 	bucket, err := getBucketByName(bucketName)
 	if err != nil {
 		return err
@@ -409,6 +418,9 @@ func (b *s3Backend) BucketExists(ctx context.Context, name string) (exists bool,
 
 // CopyObject copy specified object from srcKey to dstKey.
 func (b *s3Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, meta map[string]string) (result gofakes3.CopyObjectResult, err error) {
+	if err := checkPermission(ctx, (*model.User).CanCopy); err != nil {
+		return result, err
+	}
 	if srcBucket == dstBucket && srcKey == dstKey {
 		//TODO: update meta
 		return result, nil
@@ -7,21 +7,38 @@ import (
 	"math/rand"
 	"net/http"
 
+	"github.com/alist-org/alist/v3/pkg/utils/random"
 	"github.com/alist-org/gofakes3"
 )
 
 // Make a new S3 Server to serve the remote
 func NewServer(ctx context.Context) (h http.Handler, err error) {
 	var newLogger logger
+	authPairs := authlistResolver()
+	tokenPairs := map[string]string{random.String(32): random.String(32)}
+	tokenKeys.addBase(authPairs)
+	tokenKeys.addBase(tokenPairs)
 	faker := gofakes3.New(
 		newBackend(),
 		// gofakes3.WithHostBucket(!opt.pathBucketMode),
 		gofakes3.WithLogger(newLogger),
 		gofakes3.WithRequestID(rand.Uint64()),
 		gofakes3.WithoutVersioning(),
-		gofakes3.WithV4Auth(authlistResolver()),
+		gofakes3.WithV4Auth(authPairs),
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
-	return faker.Server(), nil
+	// requests signed with api tokens are verified by a separate faker,
+	// so that the anonymous access is kept if no access key is configured
+	tokenFaker := gofakes3.New(
+		newBackend(),
+		gofakes3.WithLogger(newLogger),
+		gofakes3.WithRequestID(rand.Uint64()),
+		gofakes3.WithoutVersioning(),
+		// a random pair only to enable the signature verification, keys of tokens are stored on demand
+		gofakes3.WithV4Auth(tokenPairs),
+		gofakes3.WithIntegrityCheck(true),
+	)
+
+	return withAPIToken(faker.Server(), tokenFaker.Server()), nil
 }
@@ -3,11 +3,16 @@ package server
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
 	"net/http"
 	"path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ipfilter"
+	"github.com/alist-org/alist/v3/internal/login"
+	"github.com/alist-org/alist/v3/internal/mfa"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -47,7 +52,16 @@ func ServeWebDAV(c *gin.Context) {
 }
 
 func WebDAVAuth(c *gin.Context) {
+	ip := ipfilter.ClientIP(c.Request)
+	if ipfilter.Check(ip, nil) != nil {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+		return
+	}
 	guest, _ := op.GetGuest()
+	var user *model.User
+	// set if authorized by an api token, either as bearer token or as the password of basic auth
+	var apiToken *model.APIToken
 	username, password, ok := c.Request.BasicAuth()
 	if !ok {
 		bt := c.GetHeader("Authorization")
@@ -63,23 +77,68 @@ func WebDAVAuth(c *gin.Context) {
 					c.Abort()
 					return
 				}
+				if ipfilter.Check(ip, admin) != nil {
+					c.Status(http.StatusForbidden)
+					c.Abort()
+					return
+				}
 				c.Set("user", admin)
 				c.Next()
 				return
 			}
+			if op.IsAPIToken(bt) {
+				if !webdavCheckLogin(c, "") {
+					return
+				}
+				var err error
+				if user, apiToken, err = op.AuthAPIToken(bt); err != nil {
+					webdavLoginFailed(c, "", err)
+				}
+			}
+		}
+		if user == nil {
+			if c.Request.Method == "OPTIONS" {
+				c.Set("user", guest)
+				c.Next()
+				return
+			}
+			c.Writer.Header()["WWW-Authenticate"] = []string{`Basic realm="alist"`}
+			c.Status(http.StatusUnauthorized)
+			c.Abort()
+			return
 		}
-		if c.Request.Method == "OPTIONS" {
-			c.Set("user", guest)
-			c.Next()
+	} else {
+		if !webdavCheckLogin(c, username) {
 			return
 		}
-		c.Writer.Header()["WWW-Authenticate"] = []string{`Basic realm="alist"`}
-		c.Status(http.StatusUnauthorized)
-		c.Abort()
-		return
+		reason := errs.WrongPassword
+		if op.IsAPIToken(password) {
+			if u, t, err := op.AuthAPIToken(password); err == nil && u.Username == username {
+				user, apiToken = u, t
+			}
+		} else if u, t, err := op.AuthAppPassword(username, password); err == nil {
+			user, apiToken = u, t
+		} else if u, err := op.GetUserByName(username); err == nil && u.ValidateRawPassword(password) == nil {
+			if mfa.Required(u) && mfa.Enrolled(u) {
+				// the account password would bypass the 2FA required by the policy
+				reason = errors.New("app password is required as 2FA is enforced")
+			} else {
+				user = u
+			}
+		}
+		if user == nil {
+			webdavLoginFailed(c, username, reason)
+		} else if login.HasFailures(user.Username, ip) {
+			// every request is authenticated, only the failures are forgotten
+			login.Succeeded(&model.LoginAttempt{
+				Username:  user.Username,
+				IP:        ip,
+				Method:    model.LoginMethodWebDAV,
+				UserAgent: c.Request.UserAgent(),
+			})
+		}
 	}
-	user, err := op.GetUserByName(username)
-	if err != nil || user.ValidateRawPassword(password) != nil {
+	if user == nil {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -99,7 +158,8 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if !user.CanWebdavManage() && utils.SliceContains([]string{"PUT", "DELETE", "PROPPATCH", "MKCOL", "COPY", "MOVE"}, c.Request.Method) {
+	write := utils.SliceContains([]string{"PUT", "DELETE", "PROPPATCH", "MKCOL", "COPY", "MOVE"}, c.Request.Method)
+	if !user.CanWebdavManage() && write {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -109,6 +169,48 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	mfa.StartGracePeriod(user)
+	if err := mfa.Check(user); err != nil {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+		return
+	}
+	if ipfilter.Check(ip, user) != nil {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+		return
+	}
+	if apiToken != nil {
+		scope := model.ScopeFsRead
+		if write {
+			scope = model.ScopeFsWrite
+		}
+		if !apiToken.HasScope(scope) {
+			c.Status(http.StatusForbidden)
+			c.Abort()
+			return
+		}
+	}
 	c.Set("user", user)
 	c.Next()
 }
+
+// webdavCheckLogin rejects the request if the ip or the account is locked
+func webdavCheckLogin(c *gin.Context, username string) bool {
+	if err := login.Check(username, ipfilter.ClientIP(c.Request)); err != nil {
+		c.Status(http.StatusTooManyRequests)
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+func webdavLoginFailed(c *gin.Context, username string, err error) {
+	login.Failed(&model.LoginAttempt{
+		Username:  username,
+		IP:        ipfilter.ClientIP(c.Request),
+		Method:    model.LoginMethodWebDAV,
+		Reason:    err.Error(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
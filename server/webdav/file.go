@@ -69,7 +69,7 @@ func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // Allowed values for depth are 0, 1 or infiniteDepth. For each visited node,
 // walkFS calls walkFn. If a visited file system node is a directory and
 // walkFn returns path.SkipDir, walkFS will skip traversal of this node.
-func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj, err error) error) error {
+func walkFS(ctx context.Context, depth int, name, ip string, info model.Obj, walkFn func(reqPath string, info model.Obj, err error) error) error {
 	// This implementation is based on Walk's code in the standard path/path package.
 	err := walkFn(name, info, nil)
 	if err != nil {
@@ -86,7 +86,7 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	}
 	meta, _ := op.GetNearestMeta(name)
 	// Read directory names.
-	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
+	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{IP: ip})
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return walkFn(name, info, err)
@@ -104,7 +104,7 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 				return err
 			}
 		} else {
-			err = walkFS(ctx, depth, filename, fileInfo, walkFn)
+			err = walkFS(ctx, depth, filename, ip, fileInfo, walkFn)
 			if err != nil {
 				if !fileInfo.IsDir() || err != filepath.SkipDir {
 					return err